@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"syscall"
+
+	"github.com/hashicorp/go-version"
+)
+
+// errorBadExeFormat is ERROR_BAD_EXE_FORMAT returned by Windows for a binary of another platform.
+const errorBadExeFormat = syscall.Errno(193)
+
+// isExecFormatError reports whether err means that the binary is not runnable on this host.
+func isExecFormatError(err error) bool {
+	switch e := err.(type) {
+	case *os.PathError:
+		err = e.Err
+	case *exec.Error:
+		err = e.Err
+	}
+
+	errno, ok := err.(syscall.Errno)
+	return ok && (errno == syscall.ENOEXEC || (runtime.GOOS == "windows" && errno == errorBadExeFormat))
+}
+
+// checkBinary runs binaryPath with "version" and verifies that it reports expectedVersion.
+func checkBinary(binaryPath, expectedVersion, goos, goarch string) error {
+	reported, err := binaryVersion(binaryPath)
+	if isExecFormatError(err) {
+		return fmt.Errorf("check failed: the binary for %s_%s cannot run on %s_%s, check --os and --arch", goos, goarch, runtime.GOOS, runtime.GOARCH)
+	} else if err != nil {
+		return fmt.Errorf("check failed: %s", err)
+	}
+
+	expected, err := version.NewVersion(expectedVersion)
+	if err != nil {
+		return err
+	}
+	actual, err := version.NewVersion(reported)
+	if err != nil {
+		return fmt.Errorf("check failed: %s", err)
+	}
+	if !actual.Equal(expected) {
+		return fmt.Errorf("check failed: the binary reports version %s instead of %s", reported, expectedVersion)
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/porkbeans/hashi/internal/ioutils"
+	"github.com/porkbeans/hashi/internal/testutils"
+)
+
+func TestCheckBinary(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake binaries are shell scripts")
+	}
+
+	tempDir, err := ioutil.TempDir("", "hashi-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	binaryPath := filepath.Join(tempDir, "terraform")
+	writeFakeBinary(t, binaryPath, "echo 'Terraform v0.11.14'")
+	if err := checkBinary(binaryPath, "0.11.14", runtime.GOOS, runtime.GOARCH); err != nil {
+		t.Errorf("error should not happen: %s", err)
+	}
+	if err := checkBinary(binaryPath, "0.11.13", runtime.GOOS, runtime.GOARCH); err == nil || !strings.Contains(err.Error(), "reports version 0.11.14") {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	writeFakeBinary(t, binaryPath, "exit 1")
+	if err := checkBinary(binaryPath, "0.11.14", runtime.GOOS, runtime.GOARCH); err == nil {
+		t.Errorf("error should happen")
+	}
+
+	if err := ioutil.WriteFile(binaryPath, []byte{0xcf, 0xfa, 0xed, 0xfe, 0x07, 0x00, 0x00, 0x01}, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkBinary(binaryPath, "0.11.14", "darwin", "amd64"); err == nil || !strings.Contains(err.Error(), "check --os and --arch") {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestInstallCmdCheckFailed(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("a darwin binary must not be runnable")
+	}
+
+	tempBinName, err := testutils.TouchTempFile()
+	if err != nil {
+		t.Fatalf("error should not happen")
+	}
+	defer ioutils.Remove(tempBinName)
+
+	defer func(originalOS, originalArch string) {
+		targetGOOS, targetGOARCH, installCheck = originalOS, originalArch, false
+	}(targetGOOS, targetGOARCH)
+	targetGOOS, targetGOARCH, installCheck = "darwin", "amd64", true
+
+	if err := installCmd.RunE(installCmd, []string{"consul", "1.4.0", tempBinName}); err == nil {
+		t.Errorf("error must happen")
+	}
+
+	if info, err := os.Stat(tempBinName); err != nil || info.Size() != 0 {
+		t.Errorf("existing file must be left unchanged")
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(tempBinName), ".hashi-new-"+filepath.Base(tempBinName))); !os.IsNotExist(err) {
+		t.Errorf("temporary file must be removed")
+	}
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var configGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Show a setting in the config file.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		value, ok := config.Get(args[0])
+		if !ok {
+			return fmt.Errorf("%s is not set", args[0])
+		}
+
+		cmd.Println(value)
+		return nil
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Change a setting in the config file.",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := config.Set(args[0], args[1]); err != nil {
+			return err
+		}
+
+		return config.Save(configFile)
+	},
+}
+
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List settings in the config file.",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		for _, key := range config.Keys() {
+			value, _ := config.Get(key)
+			if isSecretKey(key) {
+				value = "********"
+			}
+			cmd.Printf("%s=%s\n", key, value)
+		}
+
+		return nil
+	},
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage the config file.",
+	Long: `Manage the config file.
+
+Settings are applied to flags of the same name, e.g. "os", "arch" and "dir".
+Flags take precedence over environment variables (HASHI_OS, HASHI_ARCH, ...),
+which take precedence over the config file.`,
+}
+
+func init() {
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configListCmd)
+}
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/porkbeans/hashi/internal/configutils"
+	"github.com/spf13/pflag"
+)
+
+func TestConfigCmd(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "hashi-test-")
+	if err != nil {
+		t.Fatalf("error should not happen")
+	}
+	defer os.RemoveAll(tempDir)
+
+	defer func(original string) { configFile = original }(configFile)
+	configFile = filepath.Join(tempDir, "config.yaml")
+	config = configutils.New()
+
+	if err := configSetCmd.RunE(configSetCmd, []string{"os", "darwin"}); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err = configutils.Load(configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf := bytes.Buffer{}
+	configCmd.SetOutput(&buf)
+	if err := configGetCmd.RunE(configGetCmd, []string{"os"}); err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(buf.String()) != "darwin" {
+		t.Errorf("expected darwin but got %s", buf.String())
+	}
+
+	buf.Reset()
+	if err := configListCmd.RunE(configListCmd, nil); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "os=darwin\n" {
+		t.Errorf("unexpected list %s", buf.String())
+	}
+
+	if err := configGetCmd.RunE(configGetCmd, []string{"arch"}); err == nil {
+		t.Errorf("error must happen")
+	}
+}
+
+func TestBindFlags(t *testing.T) {
+	var goos, goarch, dir string
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.StringVar(&goos, "os", "linux", "")
+	flags.StringVar(&goarch, "arch", "amd64", "")
+	flags.StringVar(&dir, "dir", "", "")
+	if err := flags.Parse([]string{"--arch", "arm"}); err != nil {
+		t.Fatal(err)
+	}
+
+	c := configutils.New()
+	_ = c.Set("os", "darwin")
+	_ = c.Set("arch", "386")
+	_ = c.Set("dir", "/opt/bin")
+
+	defer os.Unsetenv(configutils.EnvName("dir"))
+	os.Setenv(configutils.EnvName("dir"), "/usr/local/bin")
+
+	if err := bindFlags(flags, c); err != nil {
+		t.Fatal(err)
+	}
+
+	if goos != "darwin" {
+		t.Errorf("os must be taken from config but got %s", goos)
+	}
+	if goarch != "arm" {
+		t.Errorf("arch must be taken from flag but got %s", goarch)
+	}
+	if dir != "/usr/local/bin" {
+		t.Errorf("dir must be taken from env but got %s", dir)
+	}
+}
+
+func TestBindFlagsInvalid(t *testing.T) {
+	var allFiles bool
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.BoolVar(&allFiles, "all-files", false, "")
+
+	c := configutils.New()
+	_ = c.Set("all-files", "invalid")
+
+	if err := bindFlags(flags, c); err == nil {
+		t.Errorf("error must happen")
+	}
+}
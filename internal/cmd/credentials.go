@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+
+	"github.com/porkbeans/hashi/internal/configutils"
+	"github.com/porkbeans/hashi/internal/httputils"
+)
+
+// credentialsSection is the config section of credentials by host.
+//
+//	credentials:
+//	  artifactory.example.com:
+//	    token: ...
+//	  mirror.example.com:
+//	    username: ...
+//	    password: ...
+const credentialsSection = "credentials"
+
+// credentialEnvName returns the environment variable of kind of credential for host.
+//
+//	token, artifactory.example.com -> HASHI_TOKEN_ARTIFACTORY_EXAMPLE_COM
+func credentialEnvName(kind, host string) string {
+	return configutils.EnvName(kind + "_" + strings.NewReplacer(":", "_").Replace(host))
+}
+
+// newCredentialLookup returns a function looking up the credential of a host
+// from environment variables, the config file and netrc in order.
+func newCredentialLookup(netrc httputils.Netrc) func(host string) (httputils.Credential, bool) {
+	return func(host string) (httputils.Credential, bool) {
+		credential := httputils.Credential{
+			Token:    os.Getenv(credentialEnvName("token", host)),
+			Username: os.Getenv(credentialEnvName("username", host)),
+			Password: os.Getenv(credentialEnvName("password", host)),
+		}
+		if len(credential.Token) > 0 || len(credential.Username) > 0 {
+			return credential, true
+		}
+
+		credential.Token, _ = config.GetPath(credentialsSection, host, "token")
+		credential.Username, _ = config.GetPath(credentialsSection, host, "username")
+		credential.Password, _ = config.GetPath(credentialsSection, host, "password")
+		if len(credential.Token) > 0 || len(credential.Username) > 0 {
+			return credential, true
+		}
+
+		return netrc.Lookup(host)
+	}
+}
+
+// isSecretKey reports whether key of the config file holds a credential.
+func isSecretKey(key string) bool {
+	return strings.HasPrefix(key, credentialsSection+".")
+}
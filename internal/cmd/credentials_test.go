@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/porkbeans/hashi/internal/configutils"
+	"github.com/porkbeans/hashi/internal/httputils"
+)
+
+func TestCredentialEnvName(t *testing.T) {
+	testCases := map[string]string{
+		"artifactory.example.com":    "HASHI_TOKEN_ARTIFACTORY_EXAMPLE_COM",
+		"mirror.example.com:8443":    "HASHI_TOKEN_MIRROR_EXAMPLE_COM_8443",
+		"internal-mirror.example.io": "HASHI_TOKEN_INTERNAL_MIRROR_EXAMPLE_IO",
+	}
+
+	for host, expected := range testCases {
+		if actual := credentialEnvName("token", host); actual != expected {
+			t.Errorf("expected %s but got %s", expected, actual)
+		}
+	}
+}
+
+func TestCredentialLookup(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "hashi-test-")
+	if err != nil {
+		t.Fatalf("error should not happen")
+	}
+	defer os.RemoveAll(tempDir)
+
+	configPath := filepath.Join(tempDir, "config.yaml")
+	content := "credentials:\n  env.example.com:\n    token: config\n  config.example.com:\n    username: alice\n    password: s3cret\n"
+	if err := ioutil.WriteFile(configPath, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	defer func(original *configutils.Config) { config = original }(config)
+	if config, err = configutils.Load(configPath); err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.Unsetenv(credentialEnvName("token", "env.example.com"))
+	os.Setenv(credentialEnvName("token", "env.example.com"), "env")
+
+	netrc := httputils.ParseNetrc("machine config.example.com login bob password netrc\nmachine netrc.example.com login carol password netrc\n")
+	lookup := newCredentialLookup(netrc)
+
+	testCases := map[string]httputils.Credential{
+		"env.example.com":    {Token: "env"},
+		"config.example.com": {Username: "alice", Password: "s3cret"},
+		"netrc.example.com":  {Username: "carol", Password: "netrc"},
+	}
+
+	for host, expected := range testCases {
+		if actual, ok := lookup(host); !ok || actual != expected {
+			t.Errorf("%s: expected %+v but got %+v", host, expected, actual)
+		}
+	}
+
+	if _, ok := lookup("unknown.example.com"); ok {
+		t.Errorf("credential must not be found")
+	}
+
+	buf := bytes.Buffer{}
+	configCmd.SetOutput(&buf)
+	if err := configListCmd.RunE(configListCmd, nil); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "s3cret") || strings.Contains(buf.String(), "config\n") {
+		t.Errorf("credentials must be masked: %s", buf.String())
+	}
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// selectVersion returns the version of product used in dir.
+func selectVersion(cmd *cobra.Command, product, dir string) (string, error) {
+	spec, source, err := findVersionSpec(product, dir)
+	if err != nil {
+		return "", err
+	}
+	logf(cmd, verbosityDetail, "Selected %s %s from %s", product, spec, source)
+
+	return resolveVersion(cmd, product, spec, dir)
+}
+
+// execProduct runs product of the version spec with args, installing it into the cache directory if needed.
+// The version is selected for the current directory if spec is empty.
+func execProduct(cmd *cobra.Command, product, spec string, args []string) error {
+	var version string
+	var err error
+	if len(spec) > 0 {
+		version, err = resolveVersion(cmd, product, spec, ".")
+	} else {
+		version, err = selectVersion(cmd, product, ".")
+	}
+	if err != nil {
+		return fmt.Errorf("hashi: %w", err)
+	}
+
+	binaryPath, err := ensureStored(cmd, product, version, targetGOOS, targetGOARCH)
+	if err != nil {
+		return fmt.Errorf("hashi: %w", err)
+	}
+
+	return execBinary(binaryPath, args)
+}
+
+// parseProductSpec splits name@version.
+func parseProductSpec(arg string) (string, string) {
+	if i := strings.Index(arg, "@"); i >= 0 {
+		return arg[:i], arg[i+1:]
+	}
+
+	return arg, ""
+}
+
+var execCmd = &cobra.Command{
+	Use:   "exec <name>[@version] [--] [args...]",
+	Short: "Run a specific version of a HashiCorp tool without installing it globally.",
+	Long: `Run a specific version of a HashiCorp tool without installing it globally.
+
+The version is downloaded into the cache directory and verified if needed,
+then executed with args. If version is omitted, it is selected for the current
+directory in the same way as shims.
+
+  hashi exec terraform@0.11.14 -- state pull`,
+	Args:          cobra.MinimumNArgs(1),
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		product, spec := parseProductSpec(args[0])
+		if len(product) == 0 || (strings.Contains(args[0], "@") && len(spec) == 0) {
+			return fmt.Errorf("invalid name %s", args[0])
+		}
+
+		args = args[1:]
+		if len(args) > 0 && args[0] == "--" {
+			args = args[1:]
+		}
+
+		return execProduct(cmd, product, spec, args)
+	},
+}
+
+func init() {
+	execCmd.Flags().SetInterspersed(false)
+}
@@ -0,0 +1,26 @@
+package cmd
+
+import "testing"
+
+func TestParseProductSpec(t *testing.T) {
+	testCases := map[string][2]string{
+		"terraform@0.11.14":   {"terraform", "0.11.14"},
+		"terraform@latest:^1": {"terraform", "latest:^1"},
+		"terraform":           {"terraform", ""},
+	}
+
+	for arg, expected := range testCases {
+		product, spec := parseProductSpec(arg)
+		if product != expected[0] || spec != expected[1] {
+			t.Errorf("%s: expected %v but got %s %s", arg, expected, product, spec)
+		}
+	}
+}
+
+func TestExecCmdInvalid(t *testing.T) {
+	for _, arg := range []string{"@0.11.14", "terraform@"} {
+		if err := execCmd.RunE(execCmd, []string{arg}); err == nil {
+			t.Errorf("%s: error must happen", arg)
+		}
+	}
+}
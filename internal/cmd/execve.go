@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"runtime"
+	"syscall"
+)
+
+// exitCodeError makes Execute return Code without printing any message.
+type exitCodeError struct {
+	Code int
+}
+
+func (e exitCodeError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+// execBinary replaces the current process with binaryPath, so that stdio, signals and the exit code are passed through.
+// On Windows, binaryPath runs as a child process instead and its exit code is returned as exitCodeError.
+func execBinary(binaryPath string, args []string) error {
+	if runtime.GOOS != "windows" {
+		return syscall.Exec(binaryPath, append([]string{binaryPath}, args...), os.Environ())
+	}
+
+	child := exec.Command(binaryPath, args...)
+	child.Stdin = os.Stdin
+	child.Stdout = os.Stdout
+	child.Stderr = os.Stderr
+
+	// Ctrl+C is delivered to the child as well because it shares the console, so just ignore it here.
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
+	defer signal.Stop(signals)
+
+	err := child.Run()
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		if status, ok := exitErr.Sys().(syscall.WaitStatus); ok {
+			return exitCodeError{Code: status.ExitStatus()}
+		}
+	}
+
+	return err
+}
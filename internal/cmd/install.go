@@ -9,35 +9,50 @@ import (
 	"io"
 	"io/ioutil"
 	"os"
-	"runtime"
-	"time"
+	"path"
+	"path/filepath"
+	"strings"
 
 	"github.com/porkbeans/hashi/internal/httputils"
 	"github.com/porkbeans/hashi/internal/ioutils"
 
-	"github.com/mitchellh/ioprogress"
+	"github.com/porkbeans/hashi/pkg/errorutils"
 	"github.com/porkbeans/hashi/pkg/parseutils"
+	"github.com/porkbeans/hashi/pkg/progressutils"
 	"github.com/porkbeans/hashi/pkg/urlutils"
 	"github.com/spf13/cobra"
 )
 
+const (
+	// defaultMaxExtractSize is the default limit of total uncompressed size for --all-files.
+	defaultMaxExtractSize = 1 << 30
+)
+
 var (
-	targetGOOS   string
-	targetGOARCH string
+	installAllFiles       bool
+	installDir            string
+	installName           string
+	installIncludes       []string
+	installExcludes       []string
+	installMaxExtractSize int64
+	installCheck          bool
 )
 
-func progressReader(reader io.Reader, size int64, printer io.Writer, prefix string) *ioprogress.Reader {
-	return &ioprogress.Reader{
-		Reader:       reader,
-		Size:         size,
-		DrawInterval: 100 * time.Millisecond,
-		DrawFunc: ioprogress.DrawTerminalf(printer, func(progress int64, total int64) string {
-			return fmt.Sprintf("%s %s\r", prefix, ioprogress.DrawTextFormatBytes(progress, total))
-		}),
+// readErrorRecorder records an error of Reader, to tell network errors from errors writing what is read.
+type readErrorRecorder struct {
+	io.Reader
+	err error
+}
+
+func (r *readErrorRecorder) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	if err != nil && err != io.EOF {
+		r.err = err
 	}
+	return n, err
 }
 
-func downloadToTempFile(url string, printer io.Writer) (string, [32]byte, error) {
+func downloadToTempFile(url string, observer progressutils.Observer) (string, [32]byte, error) {
 	checksum := [32]byte{}
 
 	resp, err := httputils.Get(nil, url)
@@ -53,11 +68,19 @@ func downloadToTempFile(url string, printer io.Writer) (string, [32]byte, error)
 	defer ioutils.Close(tempFile)
 
 	hash := sha256.New()
-	tee := io.TeeReader(resp.Body, hash)
-
-	_, err = io.Copy(tempFile, progressReader(tee, resp.ContentLength, printer, "Downloading..."))
+	body := &readErrorRecorder{Reader: resp.Body}
+	tee := io.TeeReader(body, hash)
+
+	_, err = io.Copy(tempFile, progressReader(tee, observer, progressutils.Event{
+		URL:     httputils.RedactURL(url),
+		Total:   resp.ContentLength,
+		Message: "Downloading...",
+	}))
 	if err != nil {
 		defer ioutils.Remove(tempFile.Name())
+		if body.err != nil {
+			return "", checksum, &errorutils.NetworkError{URL: httputils.RedactURL(url), Err: body.err}
+		}
 		return "", checksum, err
 	}
 
@@ -66,28 +89,78 @@ func downloadToTempFile(url string, printer io.Writer) (string, [32]byte, error)
 	return tempFile.Name(), checksum, nil
 }
 
-func getChecksum(product, version, goos, goarch string) ([32]byte, error) {
-	url := urlutils.ProductZipChecksumURL(product, version)
-
+// fetchContent retrieves the whole content of url.
+func fetchContent(url string) ([]byte, error) {
 	resp, err := httputils.Get(nil, url)
 	if err != nil {
-		return [32]byte{}, err
+		return nil, err
 	}
 	defer ioutils.Close(resp.Body)
 
-	content, err := ioutil.ReadAll(resp.Body)
+	return ioutil.ReadAll(resp.Body)
+}
+
+// fetchChecksumFile returns the SHA256SUMS file of product's version from the first mirror serving it.
+// The signature of the file is verified with the trusted keys and returned.
+func fetchChecksumFile(cmd *cobra.Command, product, version string) ([]byte, []byte, error) {
+	var content []byte
+	err := fetchFromMirrors(cmd, fmt.Sprintf("SHA256SUMS of %s %s", product, version), func(mirror urlutils.Mirror) error {
+		url, err := mirror.SumsURL(product, version)
+		if err != nil {
+			return err
+		}
+
+		content, err = fetchContent(url)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	signature, err := verifyChecksumList(cmd, product, version, content)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return content, signature, nil
+}
+
+// getChecksumList returns checksums in the SHA256SUMS file of product's version from the first mirror serving it.
+// The signature of the file is verified with the trusted keys.
+func getChecksumList(cmd *cobra.Command, product, version string) (parseutils.ChecksumList, error) {
+	content, _, err := fetchChecksumFile(cmd, product, version)
 	if err != nil {
+		return nil, err
+	}
+
+	return parseutils.ParseChecksumList(string(content)), nil
+}
+
+// getChecksum returns the published checksum of the zip of product for goos and goarch.
+// If the zip is not published, the error suggests available platforms, nearby versions or similar products.
+func getChecksum(cmd *cobra.Command, product, version, goos, goarch string) ([32]byte, error) {
+	checksums, err := getChecksumList(cmd, product, version)
+	if errors.Is(err, errorutils.ErrNotFound) {
+		return [32]byte{}, missingReleaseError(cmd, product, version, err)
+	} else if err != nil {
 		return [32]byte{}, err
 	}
 
-	checksums := parseutils.ParseChecksumList(string(content))
+	var platforms []string
 	for _, checksum := range checksums {
-		if checksum.Name == product && checksum.Version == version && checksum.Os == goos && checksum.Arch == goarch {
+		if checksum.Name != product || checksum.Version != version {
+			continue
+		}
+		if checksum.Os == goos && checksum.Arch == goarch {
 			return checksum.Checksum, nil
 		}
+		platforms = append(platforms, checksum.Os+"_"+checksum.Arch)
 	}
 
-	return [32]byte{}, errors.New("checksum not found")
+	if len(platforms) == 0 {
+		return [32]byte{}, fmt.Errorf("checksum of %s %s %w", product, version, errorutils.ErrNotFound)
+	}
+	return [32]byte{}, fmt.Errorf("%w: %s %s is not available for %s_%s, available platforms: %s", errorutils.ErrPlatformUnavailable, product, version, goos, goarch, strings.Join(platforms, ", "))
 }
 
 func openFileInZip(zipReader *zip.ReadCloser, filename string) (io.ReadCloser, *zip.File, error) {
@@ -98,10 +171,10 @@ func openFileInZip(zipReader *zip.ReadCloser, filename string) (io.ReadCloser, *
 		}
 	}
 
-	return nil, nil, fmt.Errorf("%s not found in zip", filename)
+	return nil, nil, fmt.Errorf("%s in zip %w", filename, errorutils.ErrNotFound)
 }
 
-func extractBinaryInZip(dst string, src string, filenameInZip string, printer io.Writer) error {
+func extractBinaryInZip(dst string, src string, filenameInZip string, observer progressutils.Observer) error {
 	zipReader, err := zip.OpenReader(src)
 	if err != nil {
 		return err
@@ -114,7 +187,11 @@ func extractBinaryInZip(dst string, src string, filenameInZip string, printer io
 	}
 	defer ioutils.Close(rawReader)
 
-	fileReader := progressReader(rawReader, int64(file.UncompressedSize64), printer, "Extracting...")
+	fileReader := progressReader(rawReader, observer, progressutils.Event{
+		Path:    dst,
+		Total:   int64(file.UncompressedSize64),
+		Message: "Extracting...",
+	})
 
 	fileWriter, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, os.FileMode(0755))
 	if err != nil {
@@ -122,50 +199,410 @@ func extractBinaryInZip(dst string, src string, filenameInZip string, printer io
 	}
 	defer ioutils.Close(fileWriter)
 
-	_, err = io.Copy(fileWriter, fileReader)
-	return err
+	written, err := io.Copy(fileWriter, fileReader)
+	if err != nil {
+		return err
+	}
+
+	observer.Observe(progressutils.Event{Type: progressutils.EventExtracted, Path: dst, Bytes: written})
+	return nil
+}
+
+func matchAnyPattern(patterns []string, name string) (bool, error) {
+	for _, pattern := range patterns {
+		for _, target := range []string{name, path.Base(name)} {
+			matched, err := path.Match(pattern, target)
+			if err != nil {
+				return false, err
+			}
+			if matched {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
+
+// zipEntryFilter decides which entries are extracted by extractAllInZip.
+type zipEntryFilter struct {
+	Includes []string
+	Excludes []string
+}
+
+func (f zipEntryFilter) match(name string) (bool, error) {
+	if len(f.Includes) > 0 {
+		included, err := matchAnyPattern(f.Includes, name)
+		if err != nil || !included {
+			return false, err
+		}
+	}
+
+	excluded, err := matchAnyPattern(f.Excludes, name)
+	return !excluded, err
+}
+
+func safeJoin(dir, name string) (string, error) {
+	if strings.HasPrefix(name, "/") || strings.HasPrefix(name, "\\") || filepath.IsAbs(name) || filepath.VolumeName(name) != "" {
+		return "", fmt.Errorf("%s is an absolute path", name)
+	}
+
+	for _, element := range strings.FieldsFunc(name, func(r rune) bool { return r == '/' || r == '\\' }) {
+		if element == ".." {
+			return "", fmt.Errorf("%s contains '..'", name)
+		}
+	}
+
+	return filepath.Join(dir, filepath.FromSlash(name)), nil
+}
+
+func extractFileInZip(dst string, file *zip.File, limit int64, observer progressutils.Observer) (int64, error) {
+	mode := file.Mode().Perm()
+	if mode == 0 {
+		mode = os.FileMode(0644)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dst), os.FileMode(0755)); err != nil {
+		return 0, err
+	}
+
+	rawReader, err := file.Open()
+	if err != nil {
+		return 0, err
+	}
+	defer ioutils.Close(rawReader)
+
+	fileReader := progressReader(io.LimitReader(rawReader, limit+1), observer, progressutils.Event{
+		Path:    dst,
+		Total:   int64(file.UncompressedSize64),
+		Message: fmt.Sprintf("Extracting %s...", file.Name),
+	})
+
+	fileWriter, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return 0, err
+	}
+	defer ioutils.Close(fileWriter)
+
+	written, err := io.Copy(fileWriter, fileReader)
+	if err != nil {
+		return written, err
+	}
+
+	if written > limit {
+		return written, errors.New("total uncompressed size exceeds the limit")
+	}
+
+	if err := fileWriter.Chmod(mode); err != nil {
+		return written, err
+	}
+
+	observer.Observe(progressutils.Event{Type: progressutils.EventExtracted, Path: dst, Bytes: written})
+	return written, nil
+}
+
+// extractAllInZip extracts entries in the zip file matching filter into dstDir.
+// Entries escaping dstDir are rejected, and the total uncompressed size is limited to maxSize bytes.
+func extractAllInZip(dstDir string, src string, filter zipEntryFilter, maxSize int64, observer progressutils.Observer) ([]string, error) {
+	zipReader, err := zip.OpenReader(src)
+	if err != nil {
+		return nil, err
+	}
+	defer ioutils.Close(zipReader)
+
+	var files []*zip.File
+	var targets []string
+	var totalSize uint64
+	for _, file := range zipReader.File {
+		target, err := safeJoin(dstDir, file.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		if file.Mode()&os.ModeSymlink != 0 {
+			return nil, fmt.Errorf("%s is a symbolic link", file.Name)
+		}
+
+		if file.FileInfo().IsDir() {
+			continue
+		}
+
+		matched, err := filter.match(file.Name)
+		if err != nil {
+			return nil, err
+		}
+		if !matched {
+			continue
+		}
+
+		totalSize += file.UncompressedSize64
+		if totalSize > uint64(maxSize) {
+			return nil, fmt.Errorf("total uncompressed size exceeds the limit (%d bytes)", maxSize)
+		}
+
+		files = append(files, file)
+		targets = append(targets, target)
+	}
+
+	remaining := maxSize
+	for i, file := range files {
+		written, err := extractFileInZip(targets[i], file, remaining, observer)
+		if err != nil {
+			return nil, err
+		}
+		remaining -= written
+	}
+
+	return targets, nil
+}
+
+// downloadVerifiedZip downloads the zip of product to a temporary file and verifies its checksum.
+// The zip may be served by another mirror than the SHA256SUMS file, whose checksum is always trusted.
+// Verified zips are kept in the cache directory with --keep-zips, which is the only source of zips with --offline.
+func downloadVerifiedZip(cmd *cobra.Command, product, version, goos, goarch string) (string, [32]byte, error) {
+	// The checksum is fetched first so that a missing release fails before downloading.
+	expectedChecksum, err := getChecksum(cmd, product, version, goos, goarch)
+	if err != nil {
+		return "", expectedChecksum, err
+	}
+
+	observer := newObserver(cmd)
+	artifact := fmt.Sprintf("zip of %s %s for %s_%s", product, version, goos, goarch)
+	var tempFileName, displayURL string
+	var actualChecksum [32]byte
+	if offlineMode {
+		displayURL = cachedZipPath(product, version, goos, goarch)
+		tempFileName, actualChecksum, err = copyCachedZip(product, version, goos, goarch)
+		if err != nil {
+			return "", actualChecksum, err
+		}
+		logf(cmd, verbosityDetail, "Using the %s cached at %s", artifact, displayURL)
+	} else {
+		tempFileName, displayURL, actualChecksum, err = fetchZip(cmd, observer, artifact, product, version, goos, goarch)
+		if err != nil {
+			return "", actualChecksum, err
+		}
+	}
+
+	if !bytes.Equal(expectedChecksum[:], actualChecksum[:]) {
+		ioutils.Remove(tempFileName)
+		return "", actualChecksum, fmt.Errorf("%w: %s", errorutils.ErrChecksumMismatch, displayURL)
+	}
+	observer.Observe(progressutils.Event{
+		Type:    progressutils.EventChecksumVerified,
+		Product: product,
+		Version: version,
+		URL:     displayURL,
+		Message: "Checksum Passed",
+	})
+
+	if keepZips && displayURL != cachedZipPath(product, version, goos, goarch) {
+		keepZip(cmd, tempFileName, product, version, goos, goarch)
+	}
+
+	return tempFileName, actualChecksum, nil
+}
+
+// fetchZip downloads the zip of product from mirrors to a temporary file, and returns its URL and checksum.
+// If no mirror is reachable, the zip kept in the cache directory is used instead.
+func fetchZip(cmd *cobra.Command, observer progressutils.Observer, artifact, product, version, goos, goarch string) (string, string, [32]byte, error) {
+	var tempFileName, displayURL string
+	var actualChecksum [32]byte
+	err := fetchFromMirrors(cmd, artifact, func(mirror urlutils.Mirror) error {
+		zipURL, err := mirror.ZipURL(product, version, goos, goarch)
+		if err != nil {
+			return err
+		}
+
+		displayURL = httputils.RedactURL(zipURL)
+		observer.Observe(progressutils.Event{
+			Type:    progressutils.EventDownloadStart,
+			Product: product,
+			Version: version,
+			URL:     displayURL,
+			Message: fmt.Sprintf("Retrieve %s", displayURL),
+		})
+
+		tempFileName, actualChecksum, err = downloadToTempFile(zipURL, observer)
+		return err
+	})
+	if errors.Is(err, errorutils.ErrNetwork) {
+		if cachedFileName, cachedChecksum, cacheErr := copyCachedZip(product, version, goos, goarch); cacheErr == nil {
+			displayURL = cachedZipPath(product, version, goos, goarch)
+			logf(cmd, verbosityInfo, "Warning: %s; using the %s cached at %s", err, artifact, displayURL)
+			return cachedFileName, displayURL, cachedChecksum, nil
+		}
+	}
+
+	return tempFileName, displayURL, actualChecksum, err
+}
+
+// binaryName returns the file name of product's binary for goos.
+func binaryName(name, goos string) string {
+	if goos == "windows" && filepath.Ext(name) != ".exe" {
+		return name + ".exe"
+	}
+
+	return name
+}
+
+// resolveInstallPath returns a file path to install the binary of product.
+// If dst is a directory, the binary is placed in it with the name or the product name.
+func resolveInstallPath(dst string, isDir bool, name, product, goos string) (string, error) {
+	if !isDir {
+		if strings.HasSuffix(dst, "/") || strings.HasSuffix(dst, string(filepath.Separator)) {
+			isDir = true
+		} else if info, err := os.Stat(dst); err == nil && info.IsDir() {
+			isDir = true
+		}
+	}
+
+	if !isDir {
+		if len(name) > 0 {
+			return "", fmt.Errorf("--name cannot be used with file path %s", dst)
+		}
+		return dst, nil
+	}
+
+	if len(name) == 0 {
+		name = product
+	}
+	if strings.ContainsAny(name, "/\\") {
+		return "", fmt.Errorf("invalid binary name %s", name)
+	}
+
+	return filepath.Join(dst, binaryName(name, goos)), nil
 }
 
 var installCmd = &cobra.Command{
-	Use:   "install <name> <version> <path>",
+	Use:   "install <name> <version> [path]",
 	Short: "Install HashiCorp tools.",
-	Args:  cobra.ExactArgs(3),
+	Long: `Install HashiCorp tools.
+
+If version is "auto", the newest version satisfying required_version
+in .tf files of the current directory is installed.
+
+With --check, the installed binary is run with "version" before it replaces
+the existing file, and the install fails if it cannot run on this host or
+reports another version.`,
+	Args: cobra.RangeArgs(2, 3),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		product := args[0]
-		version := args[1]
 		goos := targetGOOS
 		goarch := targetGOARCH
-		installPath := args[2]
 
-		zipURL := urlutils.ProductZipURL(product, version, goos, goarch)
-		cmd.Printf("Retrieve %s\n", zipURL)
+		installPath := installDir
+		isDir := true
+		if len(args) > 2 {
+			installPath = args[2]
+			isDir = installAllFiles
+		}
+		if len(installPath) == 0 {
+			return errors.New("install path or --dir is required")
+		}
 
-		tempFileName, actualChecksum, err := downloadToTempFile(zipURL, cmd.OutOrStderr())
-		if err != nil {
-			return err
+		if installAllFiles && installCheck {
+			return errors.New("--check cannot be used with --all-files")
+		}
+
+		if !installAllFiles {
+			var err error
+			installPath, err = resolveInstallPath(installPath, isDir, installName, product, goos)
+			if err != nil {
+				return err
+			}
 		}
-		defer ioutils.Remove(tempFileName)
 
-		expectedChecksum, err := getChecksum(product, version, goos, goarch)
+		version, err := resolveVersion(cmd, product, args[1], ".")
 		if err != nil {
 			return err
 		}
 
-		if !bytes.Equal(expectedChecksum[:], actualChecksum[:]) {
-			return errors.New("checksum failed")
+		if !installAllFiles {
+			zipChecksum, err := installBinary(cmd, product, version, goos, goarch, installPath)
+			if err != nil {
+				return err
+			}
+			recordInstall(cmd, product, version, goos, goarch, zipChecksum, []string{installPath})
+			return nil
 		}
-		cmd.Println("Checksum Passed")
 
-		if err := extractBinaryInZip(installPath, tempFileName, product, cmd.OutOrStderr()); err != nil {
+		tempFileName, zipChecksum, err := downloadVerifiedZip(cmd, product, version, goos, goarch)
+		if err != nil {
 			return err
 		}
+		defer ioutils.Remove(tempFileName)
 
-		cmd.Printf("Installed %s successfully to %s\n", product, installPath)
+		filter := zipEntryFilter{Includes: installIncludes, Excludes: installExcludes}
+		observer := newObserver(cmd)
+		files, err := extractAllInZip(installPath, tempFileName, filter, installMaxExtractSize, observer)
+		if err != nil {
+			return err
+		}
+		recordInstall(cmd, product, version, goos, goarch, zipChecksum, files)
+
+		observer.Observe(progressutils.Event{
+			Type:    progressutils.EventInstalled,
+			Product: product,
+			Version: version,
+			Path:    installPath,
+			Message: fmt.Sprintf("Extracted %d files to %s", len(files), installPath),
+		})
 		return nil
 	},
 }
 
+// installBinary downloads product and installs its binary to installPath, and returns the checksum of the zip.
+// Receipts are not recorded here, because binaries are also installed into the version store with it.
+func installBinary(cmd *cobra.Command, product, version, goos, goarch, installPath string) ([32]byte, error) {
+	tempFileName, zipChecksum, err := downloadVerifiedZip(cmd, product, version, goos, goarch)
+	if err != nil {
+		return zipChecksum, err
+	}
+	defer ioutils.Remove(tempFileName)
+
+	// Extract next to installPath and rename it, so that installPath is never left broken.
+	newPath := filepath.Join(filepath.Dir(installPath), ".hashi-new-"+filepath.Base(installPath))
+	defer ioutils.Remove(newPath)
+
+	observer := newObserver(cmd)
+	if err := extractBinaryInZip(newPath, tempFileName, binaryName(product, goos), observer); err != nil {
+		return zipChecksum, err
+	}
+
+	if installCheck {
+		if err := checkBinary(newPath, version, goos, goarch); err != nil {
+			return zipChecksum, fmt.Errorf("%w; %s is left unchanged", err, installPath)
+		}
+		observer.Observe(progressutils.Event{
+			Type:    progressutils.EventChecked,
+			Product: product,
+			Version: version,
+			Path:    installPath,
+			Message: "Check Passed",
+		})
+	}
+
+	if err := os.Rename(newPath, installPath); err != nil {
+		return zipChecksum, err
+	}
+
+	observer.Observe(progressutils.Event{
+		Type:    progressutils.EventInstalled,
+		Product: product,
+		Version: version,
+		Path:    installPath,
+		Message: fmt.Sprintf("Installed %s %s successfully to %s", product, version, installPath),
+	})
+	return zipChecksum, nil
+}
+
 func init() {
-	installCmd.Flags().StringVarP(&targetGOOS, "os", "o", runtime.GOOS, "operating system")
-	installCmd.Flags().StringVarP(&targetGOARCH, "arch", "a", runtime.GOARCH, "architecture")
+	installCmd.Flags().BoolVar(&installAllFiles, "all-files", false, "extract all files in the zip into a directory")
+	installCmd.Flags().StringVar(&installDir, "dir", "", "destination directory used when path is omitted")
+	installCmd.Flags().StringVar(&installName, "name", "", "binary name when installing to a directory")
+	installCmd.Flags().StringSliceVar(&installIncludes, "include", nil, "glob patterns of files to extract with --all-files")
+	installCmd.Flags().StringSliceVar(&installExcludes, "exclude", nil, "glob patterns of files not to extract with --all-files")
+	installCmd.Flags().BoolVar(&installCheck, "check", false, "run the version command of the installed binary to check it")
+	installCmd.Flags().Int64Var(&installMaxExtractSize, "max-extract-size", defaultMaxExtractSize, "limit of total uncompressed size in bytes with --all-files")
 }
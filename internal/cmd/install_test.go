@@ -4,13 +4,22 @@ import (
 	"archive/zip"
 	"bytes"
 	"crypto/sha256"
+	"errors"
+	"io"
 	"io/ioutil"
+	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"testing/iotest"
 
 	"github.com/porkbeans/hashi/internal/ioutils"
 	"github.com/porkbeans/hashi/internal/testutils"
+
+	"github.com/porkbeans/hashi/pkg/errorutils"
+	"github.com/porkbeans/hashi/pkg/progressutils"
 )
 
 func TestDownloadToTempFile(t *testing.T) {
@@ -24,7 +33,7 @@ func TestDownloadToTempFile(t *testing.T) {
 	)
 	defer server.Close()
 
-	tempFileName, checksum, err := downloadToTempFile(server.URL, os.Stderr)
+	tempFileName, checksum, err := downloadToTempFile(server.URL, textObserver{writer: os.Stderr})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -37,30 +46,79 @@ func TestDownloadToTempFile(t *testing.T) {
 }
 
 func TestDownloadToTempFileFail(t *testing.T) {
-	tempFileName, _, err := downloadToTempFile(testutils.GenerateInvalidURL(), os.Stderr)
+	tempFileName, _, err := downloadToTempFile(testutils.GenerateInvalidURL(), textObserver{writer: os.Stderr})
 	if err == nil {
 		defer ioutils.Remove(tempFileName)
 		t.Error("error must happen")
 	}
 }
 
+func TestDownloadToTempFileTruncated(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Content-Length", "10")
+		_, _ = writer.Write([]byte("Hello"))
+	}))
+	defer server.Close()
+
+	tempFileName, _, err := downloadToTempFile(server.URL, progressutils.Discard)
+	if !errors.Is(err, errorutils.ErrNetwork) {
+		defer ioutils.Remove(tempFileName)
+		t.Errorf("network error must happen: %v", err)
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("no space left on device")
+}
+
+func TestReadErrorRecorder(t *testing.T) {
+	reader := &readErrorRecorder{Reader: strings.NewReader("Hello")}
+	if _, err := io.Copy(failingWriter{}, reader); err == nil || reader.err != nil {
+		t.Errorf("write errors must not be recorded: %v, %v", err, reader.err)
+	}
+
+	reader = &readErrorRecorder{Reader: iotest.TimeoutReader(strings.NewReader("Hello"))}
+	if _, err := io.Copy(ioutil.Discard, iotest.OneByteReader(reader)); err == nil || reader.err != iotest.ErrTimeout {
+		t.Errorf("read errors must be recorded: %v, %v", err, reader.err)
+	}
+}
+
 func TestGetChecksum(t *testing.T) {
-	_, err := getChecksum("consul", "1.4.0", "linux", "amd64")
+	_, err := getChecksum(installCmd, "consul", "1.4.0", "linux", "amd64")
 	if err != nil {
 		t.Errorf("error should not happen")
 	}
 
-	_, err = getChecksum("unknown", "1.4.0", "linux", "amd64")
+	_, err = getChecksum(installCmd, "unknown", "1.4.0", "linux", "amd64")
 	if err == nil {
 		t.Errorf("error must happen")
 	}
 
-	_, err = getChecksum("consul", "1.4.0", "unknown", "unknown")
+	_, err = getChecksum(installCmd, "consul", "1.4.0", "unknown", "unknown")
 	if err == nil {
 		t.Errorf("error must happen")
 	}
 }
 
+func TestGetChecksumSuggestions(t *testing.T) {
+	_, err := getChecksum(installCmd, "consol", "1.4.0", "linux", "amd64")
+	if err == nil || !strings.Contains(err.Error(), "did you mean consul") {
+		t.Errorf("similar products must be suggested")
+	}
+
+	_, err = getChecksum(installCmd, "consul", "1.4.99", "linux", "amd64")
+	if err == nil || !strings.Contains(err.Error(), "nearby versions") {
+		t.Errorf("nearby versions must be suggested")
+	}
+
+	_, err = getChecksum(installCmd, "consul", "1.4.0", "linux", "unknown")
+	if err == nil || !strings.Contains(err.Error(), "linux_amd64") {
+		t.Errorf("available platforms must be suggested")
+	}
+}
+
 func TestOpenFileInZip(t *testing.T) {
 	tempFileName, err := testutils.CreateTempZip("testexe", "hello")
 	if err != nil {
@@ -123,7 +181,7 @@ func TestExtractBinaryInZip(t *testing.T) {
 	}
 	defer ioutils.Remove(tempBinName)
 
-	err = extractBinaryInZip(tempBinName, tempZipName, "testexe", os.Stderr)
+	err = extractBinaryInZip(tempBinName, tempZipName, "testexe", textObserver{writer: os.Stderr})
 	if err != nil {
 		t.Errorf("error should not happen")
 	}
@@ -142,7 +200,7 @@ func TestExtractBinaryInZipNotFound1(t *testing.T) {
 	}
 	defer ioutils.Remove(tempBinName)
 
-	err = extractBinaryInZip(tempBinName, tempZipName, "testexe", os.Stderr)
+	err = extractBinaryInZip(tempBinName, tempZipName, "testexe", textObserver{writer: os.Stderr})
 	if err == nil {
 		t.Errorf("error must happen")
 	}
@@ -161,7 +219,7 @@ func TestExtractBinaryInZipNotFound2(t *testing.T) {
 	}
 	defer ioutils.Remove(tempBinName)
 
-	err = extractBinaryInZip(tempBinName, tempZipName, "unknown", os.Stderr)
+	err = extractBinaryInZip(tempBinName, tempZipName, "unknown", textObserver{writer: os.Stderr})
 	if err == nil {
 		t.Errorf("error must happen")
 	}
@@ -192,3 +250,169 @@ func TestInstallCmdNotFound(t *testing.T) {
 		t.Errorf("error must happen")
 	}
 }
+
+func TestExtractAllInZip(t *testing.T) {
+	tempZipName, err := testutils.CreateTempZipFiles(map[string]string{
+		"testexe":         "hello",
+		"LICENSE.txt":     "license",
+		"helpers/testexe": "helper",
+	})
+	if err != nil {
+		t.Fatalf("error should not happen")
+	}
+	defer ioutils.Remove(tempZipName)
+
+	tempDir, err := ioutil.TempDir("", "hashi-test-")
+	if err != nil {
+		t.Fatalf("error should not happen")
+	}
+	defer os.RemoveAll(tempDir)
+
+	files, err := extractAllInZip(tempDir, tempZipName, zipEntryFilter{}, defaultMaxExtractSize, textObserver{writer: os.Stderr})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(files) != 3 {
+		t.Errorf("expected 3 files but got %d", len(files))
+	}
+
+	content, err := ioutil.ReadFile(filepath.Join(tempDir, "helpers", "testexe"))
+	if err != nil {
+		t.Fatalf("error should not happen")
+	}
+
+	if string(content) != "helper" {
+		t.Errorf("content must be 'helper'")
+	}
+}
+
+func TestExtractAllInZipFilter(t *testing.T) {
+	tempZipName, err := testutils.CreateTempZipFiles(map[string]string{
+		"testexe":         "hello",
+		"LICENSE.txt":     "license",
+		"helpers/testexe": "helper",
+	})
+	if err != nil {
+		t.Fatalf("error should not happen")
+	}
+	defer ioutils.Remove(tempZipName)
+
+	tempDir, err := ioutil.TempDir("", "hashi-test-")
+	if err != nil {
+		t.Fatalf("error should not happen")
+	}
+	defer os.RemoveAll(tempDir)
+
+	filter := zipEntryFilter{Includes: []string{"testexe"}, Excludes: []string{"helpers/*"}}
+	files, err := extractAllInZip(tempDir, tempZipName, filter, defaultMaxExtractSize, textObserver{writer: os.Stderr})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(files) != 1 || files[0] != filepath.Join(tempDir, "testexe") {
+		t.Errorf("only testexe must be extracted but got %v", files)
+	}
+}
+
+func TestExtractAllInZipUnsafePath(t *testing.T) {
+	for _, name := range []string{"../evil", "dir/../../evil", "/evil"} {
+		tempZipName, err := testutils.CreateTempZip(name, "evil")
+		if err != nil {
+			t.Fatalf("error should not happen")
+		}
+		defer ioutils.Remove(tempZipName)
+
+		tempDir, err := ioutil.TempDir("", "hashi-test-")
+		if err != nil {
+			t.Fatalf("error should not happen")
+		}
+		defer os.RemoveAll(tempDir)
+
+		if _, err := extractAllInZip(tempDir, tempZipName, zipEntryFilter{}, defaultMaxExtractSize, textObserver{writer: os.Stderr}); err == nil {
+			t.Errorf("%s must be rejected", name)
+		}
+	}
+}
+
+func TestExtractAllInZipSizeLimit(t *testing.T) {
+	tempZipName, err := testutils.CreateTempZipFiles(map[string]string{
+		"a": "0123456789",
+		"b": "0123456789",
+	})
+	if err != nil {
+		t.Fatalf("error should not happen")
+	}
+	defer ioutils.Remove(tempZipName)
+
+	tempDir, err := ioutil.TempDir("", "hashi-test-")
+	if err != nil {
+		t.Fatalf("error should not happen")
+	}
+	defer os.RemoveAll(tempDir)
+
+	if _, err := extractAllInZip(tempDir, tempZipName, zipEntryFilter{}, 15, textObserver{writer: os.Stderr}); err == nil {
+		t.Errorf("error must happen")
+	}
+}
+
+func TestBinaryName(t *testing.T) {
+	testCases := []struct {
+		name     string
+		goos     string
+		expected string
+	}{
+		{"terraform", "linux", "terraform"},
+		{"terraform", "windows", "terraform.exe"},
+		{"terraform.exe", "windows", "terraform.exe"},
+	}
+
+	for _, testCase := range testCases {
+		if actual := binaryName(testCase.name, testCase.goos); actual != testCase.expected {
+			t.Errorf("expected %s but got %s", testCase.expected, actual)
+		}
+	}
+}
+
+func TestResolveInstallPath(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "hashi-test-")
+	if err != nil {
+		t.Fatalf("error should not happen")
+	}
+	defer os.RemoveAll(tempDir)
+
+	testCases := []struct {
+		dst      string
+		isDir    bool
+		name     string
+		goos     string
+		expected string
+	}{
+		{tempDir, false, "", "linux", filepath.Join(tempDir, "terraform")},
+		{tempDir, false, "", "windows", filepath.Join(tempDir, "terraform.exe")},
+		{tempDir, false, "terraform-0.11", "linux", filepath.Join(tempDir, "terraform-0.11")},
+		{filepath.Join(tempDir, "new") + "/", false, "", "linux", filepath.Join(tempDir, "new", "terraform")},
+		{filepath.Join(tempDir, "tf"), false, "", "linux", filepath.Join(tempDir, "tf")},
+		{filepath.Join(tempDir, "bin"), true, "", "linux", filepath.Join(tempDir, "bin", "terraform")},
+	}
+
+	for _, testCase := range testCases {
+		actual, err := resolveInstallPath(testCase.dst, testCase.isDir, testCase.name, "terraform", testCase.goos)
+		if err != nil {
+			t.Errorf("error should not happen")
+		}
+		if actual != testCase.expected {
+			t.Errorf("expected %s but got %s", testCase.expected, actual)
+		}
+	}
+}
+
+func TestResolveInstallPathInvalidName(t *testing.T) {
+	if _, err := resolveInstallPath("/usr/local/bin/terraform", false, "tf", "terraform", "linux"); err == nil {
+		t.Errorf("error must happen")
+	}
+
+	if _, err := resolveInstallPath("/usr/local/bin", true, "../tf", "terraform", "linux"); err == nil {
+		t.Errorf("error must happen")
+	}
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/porkbeans/hashi/internal/stateutils"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	stateFile string
+)
+
+// recordInstall writes receipts of installed files to the state file.
+// Failures are only warned because the install itself has succeeded.
+func recordInstall(cmd *cobra.Command, product, version, goos, goarch string, zipChecksum [32]byte, files []string) {
+	if err := writeReceipts(product, version, goos, goarch, zipChecksum, files); err != nil {
+		logf(cmd, verbosityInfo, "Warning: failed to record the install in %s: %s", stateFile, err)
+	}
+}
+
+func writeReceipts(product, version, goos, goarch string, zipChecksum [32]byte, files []string) error {
+	state, err := stateutils.Load(stateFile)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	for _, file := range files {
+		path, err := filepath.Abs(file)
+		if err != nil {
+			return err
+		}
+
+		hash, err := stateutils.HashFile(path)
+		if err != nil {
+			return err
+		}
+
+		state.Add(stateutils.Receipt{
+			Product:     product,
+			Version:     version,
+			Os:          goos,
+			Arch:        goarch,
+			Path:        path,
+			ZipChecksum: hex.EncodeToString(zipChecksum[:]),
+			BinaryHash:  hash,
+			InstalledAt: now,
+		})
+	}
+
+	return state.Save(stateFile)
+}
+
+var installedCmd = &cobra.Command{
+	Use:   "installed",
+	Short: "List files installed by hashi.",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		state, err := stateutils.Load(stateFile)
+		if err != nil {
+			return err
+		}
+
+		writer := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+		_, _ = fmt.Fprintln(writer, "PRODUCT\tVERSION\tPLATFORM\tPATH\tINSTALLED AT")
+		for _, receipt := range state.Receipts {
+			_, _ = fmt.Fprintf(writer, "%s\t%s\t%s_%s\t%s\t%s\n",
+				receipt.Product, receipt.Version, receipt.Os, receipt.Arch, receipt.Path, receipt.InstalledAt.Local().Format(time.RFC3339))
+		}
+
+		return writer.Flush()
+	},
+}
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"io"
 	"net/url"
-	"runtime"
 
 	"github.com/porkbeans/hashi/internal/httputils"
 	"github.com/porkbeans/hashi/internal/ioutils"
@@ -45,7 +44,12 @@ func getList(client httputils.HTTPGetClient, rawURL string) (parseutils.LinkList
 		return nil, err
 	}
 
-	return parseutils.ParseLinkList(baseURL, root), err
+	linkList := parseutils.ParseLinkList(baseURL, root)
+	if len(linkList) == 0 {
+		linkList = parseutils.ParseAnchorList(baseURL, root)
+	}
+
+	return linkList, nil
 }
 
 func showLinkList(linkList parseutils.LinkList, writer io.Writer) {
@@ -63,7 +67,7 @@ func showProductVersionList(linkList parseutils.ProductVersionList, writer io.Wr
 func showProductZipList(linkList parseutils.ProductZipList, writer io.Writer) {
 	for _, zipLink := range linkList {
 		mark := ""
-		if zipLink.Os == runtime.GOOS && zipLink.Arch == runtime.GOARCH {
+		if zipLink.Os == targetGOOS && zipLink.Arch == targetGOARCH {
 			mark = "*"
 		}
 
@@ -71,11 +75,46 @@ func showProductZipList(linkList parseutils.ProductZipList, writer io.Writer) {
 	}
 }
 
+// listMirror lists versions of a product, or platforms of its version, on mirrors other than the releases site.
+// Platforms are taken from the SHA256SUMS file because mirrors may have no listing of a version.
+func listMirror(cmd *cobra.Command, args []string) error {
+	if len(args) == 1 {
+		versions, err := listVersions(cmd, args[0])
+		if err != nil {
+			return err
+		}
+
+		for _, version := range versions {
+			_, _ = fmt.Fprintln(cmd.OutOrStdout(), version)
+		}
+		return nil
+	}
+
+	checksums, err := getChecksumList(cmd, args[0], args[1])
+	if err != nil {
+		return err
+	}
+
+	zipList := parseutils.ProductZipList{}
+	for _, checksum := range checksums {
+		if checksum.Name == args[0] && checksum.Version == args[1] {
+			zipList = append(zipList, parseutils.ProductZipEntry{Name: checksum.Name, Version: checksum.Version, Os: checksum.Os, Arch: checksum.Arch})
+		}
+	}
+
+	showProductZipList(zipList, cmd.OutOrStdout())
+	return nil
+}
+
 var listCmd = &cobra.Command{
 	Use:   "list [name] [version]",
 	Short: "List HashiCorp tools.",
 	Args:  cobra.MaximumNArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) > 0 && !usesReleasesOnly() {
+			return listMirror(cmd, args)
+		}
+
 		rawURL := parseURL(args)
 		linkList, err := getList(nil, rawURL)
 		if err != nil {
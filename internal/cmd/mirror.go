@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/porkbeans/hashi/internal/httputils"
+
+	"github.com/porkbeans/hashi/pkg/errorutils"
+	"github.com/porkbeans/hashi/pkg/urlutils"
+	"github.com/spf13/cobra"
+)
+
+// mirrorsSection is the config section of mirrors by name.
+//
+//	mirrors:
+//	  artifactory:
+//	    base: https://artifactory.example.com/artifactory
+//	    listing: "{{.Base}}/generic-local/hashicorp/"
+//	    zip: "{{.Base}}/generic-local/hashicorp/{{.Product}}-{{.Version}}-{{.OS}}-{{.Arch}}.zip"
+//	    sums: "{{.Base}}/generic-local/hashicorp/{{.Product}}_{{.Version}}_SHA256SUMS"
+//	    signature: "{{.Base}}/generic-local/hashicorp/{{.Product}}_{{.Version}}_SHA256SUMS.sig"
+const mirrorsSection = "mirrors"
+
+var (
+	mirrorNames   []string
+	activeMirrors = []urlutils.Mirror{urlutils.DefaultMirror}
+)
+
+// loadMirror returns the mirror named name in the config file.
+// A URL is a mirror with the layout of releases.hashicorp.com, and "releases" is releases.hashicorp.com itself
+// unless it is overridden in the config file.
+func loadMirror(name string) (urlutils.Mirror, error) {
+	if strings.Contains(name, "://") {
+		return urlutils.Mirror{Name: httputils.RedactURL(name), Base: name}, nil
+	}
+
+	mirror := urlutils.Mirror{Name: name}
+	found := false
+	if name == urlutils.DefaultMirror.Name {
+		mirror = urlutils.DefaultMirror
+		found = true
+	}
+
+	for key, value := range map[string]*string{
+		"base":      &mirror.Base,
+		"listing":   &mirror.Listing,
+		"zip":       &mirror.Zip,
+		"sums":      &mirror.Sums,
+		"signature": &mirror.Signature,
+	} {
+		if v, ok := config.GetPath(mirrorsSection, name, key); ok {
+			*value = v
+			found = true
+		}
+	}
+
+	if !found {
+		return mirror, fmt.Errorf("mirror %s is not defined in %s", name, configFile)
+	}
+
+	return mirror, mirror.Validate()
+}
+
+// loadMirrors returns mirrors named names in order.
+func loadMirrors(names []string) ([]urlutils.Mirror, error) {
+	if len(names) == 0 {
+		return nil, errors.New("no mirror is given")
+	}
+
+	mirrors := make([]urlutils.Mirror, 0, len(names))
+	for _, name := range names {
+		mirror, err := loadMirror(name)
+		if err != nil {
+			return nil, err
+		}
+		mirrors = append(mirrors, mirror)
+	}
+
+	return mirrors, nil
+}
+
+// usesReleasesOnly reports whether artifacts are fetched from releases.hashicorp.com only.
+func usesReleasesOnly() bool {
+	return len(activeMirrors) == 1 && activeMirrors[0] == urlutils.DefaultMirror
+}
+
+// isFallbackError reports whether the next mirror should be tried after err.
+// Checksum and signature errors are not, because a mirror serving wrong artifacts must not be skipped silently.
+func isFallbackError(err error) bool {
+	return errors.Is(err, errorutils.ErrNotFound) || errors.Is(err, errorutils.ErrNetwork) || errors.Is(err, errorutils.ErrNotCached)
+}
+
+// fetchFromMirrors calls fetch with mirrors in order until it succeeds or fails with an error other than
+// not-found, network and not-cached errors. The mirror serving artifact is logged, at the info level if there are fallbacks.
+func fetchFromMirrors(cmd *cobra.Command, artifact string, fetch func(mirror urlutils.Mirror) error) error {
+	level := verbosityDetail
+	if len(activeMirrors) > 1 {
+		level = verbosityInfo
+	}
+
+	var err error
+	for i, mirror := range activeMirrors {
+		if err = fetch(mirror); err == nil {
+			logf(cmd, level, "Fetched %s from %s", artifact, mirror.Name)
+			return nil
+		}
+
+		if !isFallbackError(err) || i == len(activeMirrors)-1 {
+			break
+		}
+		logf(cmd, verbosityInfo, "Failed to fetch %s from %s: %s, trying %s", artifact, mirror.Name, err, activeMirrors[i+1].Name)
+	}
+
+	return err
+}
@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/porkbeans/hashi/internal/httputils"
+
+	"github.com/mitchellh/ioprogress"
+	"github.com/porkbeans/hashi/pkg/progressutils"
+	"github.com/spf13/cobra"
+)
+
+// Values of --log-format.
+const (
+	logFormatText = "text"
+	logFormatJSON = "json"
+)
+
+// Levels of -v.
+const (
+	verbosityInfo   = 0
+	verbosityDetail = 1
+	verbosityDebug  = 2
+)
+
+var (
+	logFormat    string
+	progressStep int
+	quiet        bool
+	verbosity    int
+
+	transportOptions httputils.TransportOptions
+)
+
+// isTerminal reports whether writer is a terminal.
+func isTerminal(writer io.Writer) bool {
+	file, ok := writer.(*os.File)
+	if !ok {
+		return false
+	}
+
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// textObserver prints messages of events.
+// Progress is redrawn in a line on a terminal, and printed as a line every --progress-step otherwise.
+type textObserver struct {
+	writer io.Writer
+	tty    bool
+}
+
+func (o textObserver) Observe(event progressutils.Event) {
+	switch event.Type {
+	case progressutils.EventProgress:
+		o.drawProgress(event)
+	case progressutils.EventExtracted:
+		if verbosity >= verbosityDetail {
+			_, _ = fmt.Fprintf(o.writer, "Extracted %s (%d bytes)\n", event.Path, event.Bytes)
+		}
+	default:
+		if len(event.Message) > 0 {
+			_, _ = fmt.Fprintln(o.writer, event.Message)
+		}
+	}
+}
+
+func (o textObserver) drawProgress(event progressutils.Event) {
+	if !o.tty {
+		_, _ = fmt.Fprintf(o.writer, "%s %s (%d%%)\n", event.Message, ioprogress.DrawTextFormatBytes(event.Bytes, event.Total), event.Percent)
+		return
+	}
+
+	_, _ = fmt.Fprintf(o.writer, "%s %s\r", event.Message, ioprogress.DrawTextFormatBytes(event.Bytes, event.Total))
+	if event.Bytes == event.Total {
+		_, _ = fmt.Fprintln(o.writer)
+	}
+}
+
+// newObserver returns an Observer for --log-format.
+// Events are written to stderr as other messages are, so that stdout only has results of commands.
+// Only errors are reported with --quiet.
+func newObserver(cmd *cobra.Command) progressutils.Observer {
+	var observer progressutils.Observer
+	if logFormat == logFormatJSON {
+		observer = progressutils.NewJSONObserver(cmd.OutOrStderr())
+	} else {
+		observer = textObserver{writer: cmd.OutOrStderr(), tty: isTerminal(cmd.OutOrStderr())}
+	}
+
+	if !quiet {
+		return observer
+	}
+	return progressutils.ObserverFunc(func(event progressutils.Event) {
+		if event.Type == progressutils.EventError {
+			observer.Observe(event)
+		}
+	})
+}
+
+// logf reports a message if -v is at least level.
+func logf(cmd *cobra.Command, level int, format string, args ...interface{}) {
+	if verbosity < level {
+		return
+	}
+
+	newObserver(cmd).Observe(progressutils.Event{
+		Type:    progressutils.EventLog,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// progressStepFor returns the percentage between progress events reported to observer.
+// Progress bars on a terminal are redrawn every percent.
+func progressStepFor(observer progressutils.Observer) int {
+	if text, ok := observer.(textObserver); ok && text.tty {
+		return 1
+	}
+	return progressStep
+}
+
+func progressReader(reader io.Reader, observer progressutils.Observer, event progressutils.Event) io.Reader {
+	return &progressutils.Reader{
+		Reader:   reader,
+		Observer: observer,
+		Event:    event,
+		Step:     progressStepFor(observer),
+	}
+}
+
+// setupHTTPClient configures TLS, proxies and caching of HTTP requests, traces them with --trace, and logs them with -vv.
+func setupHTTPClient(cmd *cobra.Command) error {
+	transport, err := httputils.NewTransport(transportOptions)
+	if err != nil {
+		return err
+	}
+	if transportOptions.InsecureSkipVerify {
+		_, _ = fmt.Fprintln(cmd.OutOrStderr(), "WARNING: TLS certificates are NOT verified because of --insecure-skip-verify. Downloads can be tampered with.")
+	}
+
+	netrc, err := httputils.LoadNetrc(httputils.NetrcPath())
+	if err != nil {
+		return err
+	}
+
+	client := &http.Client{Transport: &httputils.CacheTransport{
+		Base:      &httputils.AuthTransport{Base: transport, Lookup: newCredentialLookup(netrc)},
+		Dir:       httpCacheDir(),
+		TTL:       cacheTTL,
+		Refresh:   refreshCache,
+		Offline:   offlineMode,
+		Cacheable: isCacheableRequest,
+		Logf: func(format string, args ...interface{}) {
+			logf(cmd, verbosityDetail, format, args...)
+		},
+		Warnf: func(format string, args ...interface{}) {
+			logf(cmd, verbosityInfo, "Warning: "+format, args...)
+		},
+	}}
+	httputils.DefaultClient = client
+	httpTracer = nil
+	if traceEnabled {
+		httpTracer = &httputils.TracingClient{Client: client}
+		httputils.DefaultClient = httpTracer
+	}
+
+	if verbosity >= verbosityDebug {
+		httputils.DefaultClient = &httputils.LoggingClient{
+			Client: httputils.DefaultClient,
+			Logf: func(format string, args ...interface{}) {
+				logf(cmd, verbosityDebug, format, args...)
+			},
+		}
+	}
+
+	return nil
+}
+
+// validateLogFormat checks --log-format.
+func validateLogFormat() error {
+	if logFormat != logFormatText && logFormat != logFormatJSON {
+		return fmt.Errorf("invalid --log-format %s, must be %s or %s", logFormat, logFormatText, logFormatJSON)
+	}
+	return nil
+}
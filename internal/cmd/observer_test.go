@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/porkbeans/hashi/internal/httputils"
+	"github.com/porkbeans/hashi/internal/ioutils"
+	"github.com/porkbeans/hashi/internal/testutils"
+	"github.com/porkbeans/hashi/pkg/progressutils"
+)
+
+func TestTextObserver(t *testing.T) {
+	buf := new(bytes.Buffer)
+	observer := textObserver{writer: buf, tty: true}
+
+	observer.Observe(progressutils.Event{Type: progressutils.EventDownloadStart, Message: "Retrieve https://example.com"})
+	observer.Observe(progressutils.Event{Type: progressutils.EventExtracted, Path: "/tmp/vault"})
+	observer.Observe(progressutils.Event{Type: progressutils.EventProgress, Bytes: 5, Total: 10, Message: "Downloading..."})
+	observer.Observe(progressutils.Event{Type: progressutils.EventProgress, Bytes: 10, Total: 10, Message: "Downloading..."})
+
+	lines := strings.Split(buf.String(), "\n")
+	if lines[0] != "Retrieve https://example.com" {
+		t.Errorf("unexpected message %s", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], "Downloading...") || !strings.Contains(lines[1], "\r") {
+		t.Errorf("progress must be drawn in a line: %q", lines[1])
+	}
+	if len(lines) != 3 {
+		t.Errorf("events without messages must not be printed: %q", buf.String())
+	}
+}
+
+func TestTextObserverNotTerminal(t *testing.T) {
+	buf := new(bytes.Buffer)
+	observer := textObserver{writer: buf}
+
+	observer.Observe(progressutils.Event{Type: progressutils.EventProgress, Bytes: 5, Total: 10, Percent: 50, Message: "Downloading..."})
+	observer.Observe(progressutils.Event{Type: progressutils.EventProgress, Bytes: 10, Total: 10, Percent: 100, Message: "Downloading..."})
+
+	if strings.Contains(buf.String(), "\r") {
+		t.Errorf("progress must not be redrawn")
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 || !strings.HasSuffix(lines[0], "(50%)") {
+		t.Errorf("progress must be printed in lines: %q", buf.String())
+	}
+}
+
+func TestIsTerminal(t *testing.T) {
+	if isTerminal(new(bytes.Buffer)) {
+		t.Errorf("buffer is not a terminal")
+	}
+
+	file, err := ioutil.TempFile("", "hashi-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ioutils.Remove(file.Name())
+	defer ioutils.Close(file)
+
+	if isTerminal(file) {
+		t.Errorf("regular file is not a terminal")
+	}
+}
+
+func TestLogf(t *testing.T) {
+	defer func() { quiet, verbosity = false, 0 }()
+
+	buf := new(bytes.Buffer)
+	installCmd.SetOutput(buf)
+	defer installCmd.SetOutput(nil)
+
+	logf(installCmd, verbosityInfo, "info")
+	logf(installCmd, verbosityDetail, "detail")
+	verbosity = verbosityDetail
+	logf(installCmd, verbosityDetail, "detail %d", 1)
+	logf(installCmd, verbosityDebug, "debug")
+	if buf.String() != "info\ndetail 1\n" {
+		t.Errorf("unexpected logs %q", buf.String())
+	}
+
+	buf.Reset()
+	quiet = true
+	logf(installCmd, verbosityInfo, "info")
+	newObserver(installCmd).Observe(progressutils.Event{Type: progressutils.EventInstalled, Message: "installed"})
+	if buf.Len() != 0 {
+		t.Errorf("nothing must be printed with --quiet: %q", buf.String())
+	}
+}
+
+func TestNewObserverJSON(t *testing.T) {
+	defer func() { logFormat = logFormatText }()
+	logFormat = logFormatJSON
+
+	if err := validateLogFormat(); err != nil {
+		t.Errorf("error should not happen")
+	}
+
+	buf := new(bytes.Buffer)
+	installCmd.SetOutput(buf)
+	defer installCmd.SetOutput(nil)
+
+	tempZipName, err := testutils.CreateTempZip("testexe", "hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ioutils.Remove(tempZipName)
+
+	tempDir, err := ioutil.TempDir("", "hashi-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	if _, err := extractAllInZip(tempDir, tempZipName, zipEntryFilter{}, defaultMaxExtractSize, newObserver(installCmd)); err != nil {
+		t.Fatalf("error should not happen: %s", err)
+	}
+
+	var types []string
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var event progressutils.Event
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("each line must be JSON: %q", line)
+		}
+		types = append(types, event.Type)
+	}
+
+	if types[len(types)-1] != progressutils.EventExtracted {
+		t.Errorf("extracted event must be emitted: %v", types)
+	}
+}
+
+func TestValidateLogFormat(t *testing.T) {
+	defer func() { logFormat = logFormatText }()
+	logFormat = "yaml"
+
+	if err := validateLogFormat(); err == nil {
+		t.Errorf("error must happen")
+	}
+}
+
+func TestSetupHTTPClient(t *testing.T) {
+	defer func(original httputils.TransportOptions, originalClient httputils.HTTPGetClient) {
+		transportOptions, traceEnabled = original, false
+		httputils.DefaultClient, httpTracer = originalClient, nil
+	}(transportOptions, httputils.DefaultClient)
+
+	buf := new(bytes.Buffer)
+	installCmd.SetOutput(buf)
+	defer installCmd.SetOutput(nil)
+
+	transportOptions = httputils.TransportOptions{InsecureSkipVerify: true}
+	traceEnabled = true
+	if err := setupHTTPClient(installCmd); err != nil {
+		t.Fatalf("error should not happen: %s", err)
+	}
+	if !strings.Contains(buf.String(), "WARNING") {
+		t.Errorf("--insecure-skip-verify must be warned")
+	}
+	if httputils.DefaultClient != httpTracer || httpTracer == nil {
+		t.Errorf("requests must be traced")
+	}
+
+	transportOptions = httputils.TransportOptions{ClientCert: "client.pem"}
+	if err := setupHTTPClient(installCmd); err == nil {
+		t.Errorf("error must happen")
+	}
+}
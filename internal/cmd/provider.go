@@ -0,0 +1,200 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/porkbeans/hashi/internal/ioutils"
+
+	"github.com/porkbeans/hashi/pkg/progressutils"
+	"github.com/porkbeans/hashi/pkg/providerutils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	providerPluginDir string
+	providerPacked    bool
+	providerPlatforms []string
+	providerLockFile  string
+)
+
+// defaultPluginDir returns the implied local mirror directory of Terraform.
+func defaultPluginDir() string {
+	return filepath.Join(os.Getenv("HOME"), ".terraform.d", "plugins")
+}
+
+var providerInstallCmd = &cobra.Command{
+	Use:   "install <provider> <version>",
+	Short: "Install a Terraform provider into a plugin directory.",
+	Long: `Install a Terraform provider into a plugin directory.
+
+The provider is placed in the unpacked layout of a filesystem mirror, e.g.
+<plugin-dir>/registry.terraform.io/hashicorp/aws/5.0.0/linux_amd64/,
+which can be used by "terraform init -plugin-dir <plugin-dir>".
+
+With --packed, the zip is placed in the packed layout instead, e.g.
+<plugin-dir>/registry.terraform.io/hashicorp/aws/terraform-provider-aws_5.0.0_linux_amd64.zip,
+which can be served by "hashi serve --provider-mirror".`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		provider, err := providerutils.ParseProvider(args[0])
+		if err != nil {
+			return err
+		}
+		version := args[1]
+		goos := targetGOOS
+		goarch := targetGOARCH
+
+		tempFileName, zipChecksum, err := downloadVerifiedZip(cmd, provider.ProductName(), version, goos, goarch)
+		if err != nil {
+			return err
+		}
+		defer ioutils.Remove(tempFileName)
+
+		observer := newObserver(cmd)
+		if providerPacked {
+			zipPath := provider.PackedPath(providerPluginDir, version, goos, goarch)
+			if err := ioutils.CopyFile(zipPath, tempFileName, os.FileMode(0644)); err != nil {
+				return err
+			}
+			recordInstall(cmd, provider.ProductName(), version, goos, goarch, zipChecksum, []string{zipPath})
+
+			observer.Observe(progressutils.Event{
+				Type:    progressutils.EventInstalled,
+				Product: provider.ProductName(),
+				Version: version,
+				Path:    zipPath,
+				Message: fmt.Sprintf("Installed %s %s successfully to %s", provider, version, zipPath),
+			})
+			return nil
+		}
+
+		dir := provider.UnpackedDir(providerPluginDir, version, goos, goarch)
+		files, err := extractAllInZip(dir, tempFileName, zipEntryFilter{}, defaultMaxExtractSize, observer)
+		if err != nil {
+			return err
+		}
+		recordInstall(cmd, provider.ProductName(), version, goos, goarch, zipChecksum, files)
+
+		observer.Observe(progressutils.Event{
+			Type:    progressutils.EventInstalled,
+			Product: provider.ProductName(),
+			Version: version,
+			Path:    dir,
+			Message: fmt.Sprintf("Installed %s %s successfully to %s", provider, version, dir),
+		})
+		return nil
+	},
+}
+
+// lockEntry computes "zh:" hashes of all platforms and "h1:" hashes of specified platforms.
+func lockEntry(cmd *cobra.Command, provider providerutils.Provider, version string, platforms []string) (providerutils.LockEntry, error) {
+	entry := providerutils.LockEntry{Provider: provider.String(), Version: version}
+
+	targets := make([][]string, 0, len(platforms))
+	for _, platform := range platforms {
+		parts := strings.SplitN(platform, "_", 2)
+		if len(parts) != 2 || len(parts[0]) == 0 || len(parts[1]) == 0 {
+			return entry, fmt.Errorf("invalid platform %s", platform)
+		}
+		targets = append(targets, parts)
+	}
+
+	checksums, err := getChecksumList(cmd, provider.ProductName(), version)
+	if err != nil {
+		return entry, err
+	}
+	for _, checksum := range checksums {
+		if checksum.Name == provider.ProductName() && checksum.Version == version {
+			entry.Hashes = append(entry.Hashes, providerutils.ZipHash(checksum.Checksum[:]))
+		}
+	}
+
+	for _, target := range targets {
+		tempFileName, _, err := downloadVerifiedZip(cmd, provider.ProductName(), version, target[0], target[1])
+		if err != nil {
+			return entry, err
+		}
+
+		hash, err := providerutils.Hash1Zip(tempFileName)
+		ioutils.Remove(tempFileName)
+		if err != nil {
+			return entry, err
+		}
+		entry.Hashes = append(entry.Hashes, hash)
+	}
+
+	return entry, nil
+}
+
+func readLockFile(filename string) (*providerutils.LockFile, error) {
+	content, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return &providerutils.LockFile{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return providerutils.ParseLockFile(string(content))
+}
+
+var providerLockCmd = &cobra.Command{
+	Use:   "lock <provider> <version>",
+	Short: "Write hashes of a Terraform provider to the dependency lock file.",
+	Long: `Write hashes of a Terraform provider to the dependency lock file.
+
+"zh:" hashes of all platforms are taken from the SHA256SUMS file, and
+"h1:" hashes are computed from zips of platforms given by --platform.
+Existing hashes of the same version are kept.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		provider, err := providerutils.ParseProvider(args[0])
+		if err != nil {
+			return err
+		}
+		version := args[1]
+
+		platforms := providerPlatforms
+		if len(platforms) == 0 {
+			platforms = []string{providerutils.Platform(targetGOOS, targetGOARCH)}
+		}
+
+		lockFile, err := readLockFile(providerLockFile)
+		if err != nil {
+			return err
+		}
+
+		entry, err := lockEntry(cmd, provider, version, platforms)
+		if err != nil {
+			return err
+		}
+		lockFile.Merge(entry)
+
+		if err := ioutil.WriteFile(providerLockFile, []byte(lockFile.String()), os.FileMode(0644)); err != nil {
+			return err
+		}
+
+		logf(cmd, verbosityInfo, "Locked %s %s in %s", provider, version, providerLockFile)
+		return nil
+	},
+}
+
+var providerCmd = &cobra.Command{
+	Use:   "provider",
+	Short: "Manage Terraform providers.",
+}
+
+func init() {
+	providerCmd.PersistentFlags().StringVar(&providerPluginDir, "plugin-dir", defaultPluginDir(), "plugin directory")
+
+	providerInstallCmd.Flags().BoolVar(&providerPacked, "packed", false, "place the zip in the packed layout")
+
+	providerLockCmd.Flags().StringSliceVar(&providerPlatforms, "platform", nil, "platforms to compute h1 hashes, e.g. linux_amd64 (default: --os and --arch)")
+	providerLockCmd.Flags().StringVar(&providerLockFile, "lock-file", ".terraform.lock.hcl", "dependency lock file")
+
+	providerCmd.AddCommand(providerInstallCmd)
+	providerCmd.AddCommand(providerLockCmd)
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestProviderInstallCmd(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "hashi-test-")
+	if err != nil {
+		t.Fatalf("error should not happen")
+	}
+	defer os.RemoveAll(tempDir)
+
+	defer func(original string) { providerPluginDir = original }(providerPluginDir)
+	providerPluginDir = tempDir
+
+	err = providerInstallCmd.RunE(providerInstallCmd, []string{"hashicorp/null", "3.2.1"})
+	if err != nil {
+		t.Fatalf("error should not happen")
+	}
+
+	dir := filepath.Join(tempDir, "registry.terraform.io", "hashicorp", "null", "3.2.1", targetGOOS+"_"+targetGOARCH)
+	files, err := ioutil.ReadDir(dir)
+	if err != nil || len(files) == 0 {
+		t.Errorf("provider must be installed in %s", dir)
+	}
+}
+
+func TestProviderInstallCmdInvalid(t *testing.T) {
+	err := providerInstallCmd.RunE(providerInstallCmd, []string{"a/b/c/d", "1.0.0"})
+	if err == nil {
+		t.Errorf("error must happen")
+	}
+}
+
+func TestProviderLockCmd(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "hashi-test-")
+	if err != nil {
+		t.Fatalf("error should not happen")
+	}
+	defer os.RemoveAll(tempDir)
+
+	defer func(original string) { providerLockFile = original }(providerLockFile)
+	providerLockFile = filepath.Join(tempDir, ".terraform.lock.hcl")
+
+	err = providerLockCmd.RunE(providerLockCmd, []string{"hashicorp/null", "3.2.1"})
+	if err != nil {
+		t.Fatalf("error should not happen")
+	}
+
+	lockFile, err := readLockFile(providerLockFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(lockFile.Entries) != 1 || lockFile.Entries[0].Provider != "registry.terraform.io/hashicorp/null" {
+		t.Errorf("unexpected lock file %+v", lockFile)
+	}
+}
+
+func TestProviderLockCmdInvalidPlatform(t *testing.T) {
+	defer func(original []string) { providerPlatforms = original }(providerPlatforms)
+	providerPlatforms = []string{"linux"}
+
+	err := providerLockCmd.RunE(providerLockCmd, []string{"hashicorp/null", "3.2.1"})
+	if err == nil {
+		t.Errorf("error must happen")
+	}
+}
@@ -0,0 +1,479 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/porkbeans/hashi/internal/ioutils"
+
+	"github.com/porkbeans/hashi/pkg/errorutils"
+	"github.com/porkbeans/hashi/pkg/parseutils"
+	"github.com/porkbeans/hashi/pkg/progressutils"
+	"github.com/porkbeans/hashi/pkg/urlutils"
+	"github.com/spf13/cobra"
+)
+
+const (
+	defaultListingTTL = 5 * time.Minute
+
+	// proxySegment matches a product, a version or a file name in URLs served by proxyHandler.
+	proxySegment = `[A-Za-z0-9][A-Za-z0-9._+-]*`
+)
+
+var (
+	proxyAddr       string
+	proxyStore      string
+	proxyListingTTL time.Duration
+
+	proxyPaths       = regexp.MustCompile(`^/(?:(` + proxySegment + `)/(?:(` + proxySegment + `)/(` + proxySegment + `)?)?)?$`)
+	proxyProductName = regexp.MustCompile(`^` + proxySegment + `$`)
+
+	listingTemplate = template.Must(template.New("listing").Parse(`<!DOCTYPE html>
+<html>
+<head><title>{{.Title}}</title></head>
+<body>
+<ul>
+{{- range .Links}}
+<li><a href="{{.URL}}">{{.Name}}</a></li>
+{{- end}}
+</ul>
+</body>
+</html>
+`))
+)
+
+// flight is a call of flightGroup in progress.
+type flight struct {
+	done chan struct{}
+	err  error
+}
+
+// flightGroup coalesces concurrent calls with the same key into one.
+type flightGroup struct {
+	mutex   sync.Mutex
+	flights map[string]*flight
+}
+
+// do calls fn unless a call with key is in progress, and returns its error after it finishes.
+func (g *flightGroup) do(key string, fn func() error) error {
+	g.mutex.Lock()
+	if g.flights == nil {
+		g.flights = map[string]*flight{}
+	}
+	if f, ok := g.flights[key]; ok {
+		g.mutex.Unlock()
+		<-f.done
+		return f.err
+	}
+
+	f := &flight{done: make(chan struct{})}
+	g.flights[key] = f
+	g.mutex.Unlock()
+
+	f.err = fn()
+	close(f.done)
+
+	g.mutex.Lock()
+	delete(g.flights, key)
+	g.mutex.Unlock()
+
+	return f.err
+}
+
+// cachedListing is a listing page rendered by proxyHandler.
+type cachedListing struct {
+	content []byte
+	fetched time.Time
+}
+
+// proxyHandler serves the URL space of releases.hashicorp.com from a store directory.
+// Missing SHA256SUMS files and zips are fetched from the mirrors on the first request,
+// and stored only after they are verified in the same way as installs.
+// Listings are rendered from the mirrors and cached in memory for the TTL.
+type proxyHandler struct {
+	cmd        *cobra.Command
+	store      string
+	listingTTL time.Duration
+
+	flights  flightGroup
+	mutex    sync.Mutex
+	listings map[string]cachedListing
+}
+
+// newProxyHandler creates a proxyHandler storing artifacts in store.
+func newProxyHandler(cmd *cobra.Command, store string, listingTTL time.Duration) *proxyHandler {
+	return &proxyHandler{
+		cmd:        cmd,
+		store:      store,
+		listingTTL: listingTTL,
+		listings:   map[string]cachedListing{},
+	}
+}
+
+// proxyStatus returns the HTTP status code for err.
+func proxyStatus(err error) int {
+	switch {
+	case errors.Is(err, errorutils.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, errorutils.ErrNetwork), errors.Is(err, errorutils.ErrChecksumMismatch), errors.Is(err, errorutils.ErrSignatureInvalid):
+		return http.StatusBadGateway
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func (h *proxyHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodGet && request.Method != http.MethodHead {
+		http.Error(writer, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	matches := proxyPaths.FindStringSubmatch(request.URL.Path)
+	if matches == nil {
+		http.NotFound(writer, request)
+		return
+	}
+
+	var err error
+	switch product, version, name := matches[1], matches[2], matches[3]; {
+	case len(name) > 0:
+		err = h.serveFile(writer, request, product, version, name)
+	case len(version) > 0:
+		err = h.serveListing(writer, request, func() ([]parseutils.LinkEntry, error) { return h.fileLinks(product, version) })
+	case len(product) > 0:
+		err = h.serveListing(writer, request, func() ([]parseutils.LinkEntry, error) { return h.versionLinks(product) })
+	default:
+		err = h.serveListing(writer, request, h.productLinks)
+	}
+
+	if err != nil {
+		logf(h.cmd, verbosityInfo, "Failed to serve %s: %s", request.URL.Path, err)
+		http.Error(writer, err.Error(), proxyStatus(err))
+		return
+	}
+	logf(h.cmd, verbosityDetail, "Served %s", request.URL.Path)
+}
+
+// serveListing serves a listing page rendered from links, which are fetched again after the TTL.
+// A stale page is served if the mirrors fail.
+func (h *proxyHandler) serveListing(writer http.ResponseWriter, request *http.Request, links func() ([]parseutils.LinkEntry, error)) error {
+	key := request.URL.Path
+
+	h.mutex.Lock()
+	listing, cached := h.listings[key]
+	h.mutex.Unlock()
+
+	if !cached || time.Since(listing.fetched) >= h.listingTTL {
+		err := h.flights.do("listing:"+key, func() error {
+			linkList, err := links()
+			if err != nil {
+				return err
+			}
+
+			buf := &bytes.Buffer{}
+			if err := listingTemplate.Execute(buf, map[string]interface{}{"Title": key, "Links": linkList}); err != nil {
+				return err
+			}
+
+			h.mutex.Lock()
+			h.listings[key] = cachedListing{content: buf.Bytes(), fetched: time.Now()}
+			h.mutex.Unlock()
+			return nil
+		})
+
+		if err != nil && !cached {
+			return err
+		} else if err != nil {
+			logf(h.cmd, verbosityInfo, "Serving a stale listing of %s fetched at %s: %s", key, listing.fetched.Format(time.RFC3339), err)
+		}
+
+		h.mutex.Lock()
+		listing = h.listings[key]
+		h.mutex.Unlock()
+	}
+
+	writer.Header().Set("Content-Type", "text/html; charset=utf-8")
+	http.ServeContent(writer, request, "", listing.fetched, bytes.NewReader(listing.content))
+	return nil
+}
+
+// productLinks returns links to products listed by the first mirror with the layout of releases.hashicorp.com.
+func (h *proxyHandler) productLinks() ([]parseutils.LinkEntry, error) {
+	var links []parseutils.LinkEntry
+	err := fetchFromMirrors(h.cmd, "products", func(mirror urlutils.Mirror) error {
+		linkList, err := getList(nil, strings.TrimSuffix(mirror.Base, "/")+"/")
+		if err != nil {
+			return err
+		}
+
+		links = nil
+		for _, link := range linkList {
+			if proxyProductName.MatchString(link.Name) {
+				links = append(links, parseutils.LinkEntry{Name: link.Name, URL: "/" + link.Name + "/"})
+			}
+		}
+		return nil
+	})
+
+	return links, err
+}
+
+// versionLinks returns links to versions of product.
+func (h *proxyHandler) versionLinks(product string) ([]parseutils.LinkEntry, error) {
+	versions, err := listVersions(h.cmd, product)
+	if err != nil {
+		return nil, err
+	}
+
+	links := make([]parseutils.LinkEntry, 0, len(versions))
+	for _, version := range versions {
+		links = append(links, parseutils.LinkEntry{Name: product + "_" + version, URL: "/" + product + "/" + version + "/"})
+	}
+
+	return links, nil
+}
+
+// fileLinks returns links to zips in the SHA256SUMS file of product's version, the file itself and its signature.
+func (h *proxyHandler) fileLinks(product, version string) ([]parseutils.LinkEntry, error) {
+	checksums, err := h.ensureChecksumList(product, version)
+	if err != nil {
+		return nil, err
+	}
+
+	var links []parseutils.LinkEntry
+	for _, checksum := range checksums {
+		if checksum.Name == product && checksum.Version == version {
+			name := zipFileName(product, version, checksum.Os, checksum.Arch)
+			links = append(links, parseutils.LinkEntry{Name: name, URL: "/" + product + "/" + version + "/" + name})
+		}
+	}
+
+	sumsName := sumsFileName(product, version)
+	for _, name := range []string{sumsName, sumsName + ".sig"} {
+		links = append(links, parseutils.LinkEntry{Name: name, URL: "/" + product + "/" + version + "/" + name})
+	}
+
+	return links, nil
+}
+
+func zipFileName(product, version, goos, goarch string) string {
+	return fmt.Sprintf("%s_%s_%s_%s.zip", product, version, goos, goarch)
+}
+
+func sumsFileName(product, version string) string {
+	return fmt.Sprintf("%s_%s_SHA256SUMS", product, version)
+}
+
+// storePath returns a path of a file of product's version in the store.
+func (h *proxyHandler) storePath(product, version, name string) string {
+	return filepath.Join(h.store, product, version, name)
+}
+
+// writeStoreFile writes content to path atomically.
+func writeStoreFile(path string, content []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), os.FileMode(0755)); err != nil {
+		return err
+	}
+
+	tempPath := path + ".hashi-new"
+	if err := ioutil.WriteFile(tempPath, content, os.FileMode(0644)); err != nil {
+		ioutils.Remove(tempPath)
+		return err
+	}
+
+	return os.Rename(tempPath, path)
+}
+
+// copyStoreFile copies src to path atomically.
+func copyStoreFile(path, src string) error {
+	if err := os.MkdirAll(filepath.Dir(path), os.FileMode(0755)); err != nil {
+		return err
+	}
+
+	tempPath := path + ".hashi-new"
+	if err := ioutils.CopyFile(tempPath, src, os.FileMode(0644)); err != nil {
+		ioutils.Remove(tempPath)
+		return err
+	}
+
+	return os.Rename(tempPath, path)
+}
+
+// ensureChecksumList returns checksums of product's version, storing the SHA256SUMS file and its signature
+// after the signature is verified with the trusted keys unless both are already stored.
+func (h *proxyHandler) ensureChecksumList(product, version string) (parseutils.ChecksumList, error) {
+	path := h.storePath(product, version, sumsFileName(product, version))
+	err := h.flights.do(path, func() error {
+		if _, err := os.Stat(path + ".sig"); err == nil {
+			if _, err := os.Stat(path); err == nil {
+				return nil
+			}
+		}
+
+		content, signature, err := fetchChecksumFile(h.cmd, product, version)
+		if err != nil {
+			return err
+		}
+
+		if err := writeStoreFile(path+".sig", signature); err != nil {
+			return err
+		}
+		return writeStoreFile(path, content)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseutils.ParseChecksumList(string(content)), nil
+}
+
+// ensureSignature stores the signature of the SHA256SUMS file of product's version unless it is already stored.
+// The signature is only stored together with the SHA256SUMS file it is verified against.
+func (h *proxyHandler) ensureSignature(product, version string) error {
+	_, err := h.ensureChecksumList(product, version)
+	return err
+}
+
+// ensureZip stores the zip of product's version for goos and goarch after its checksum is verified
+// unless it is already stored.
+func (h *proxyHandler) ensureZip(product, version, goos, goarch string) error {
+	checksums, err := h.ensureChecksumList(product, version)
+	if err != nil {
+		return err
+	}
+
+	var expectedChecksum *[32]byte
+	for _, checksum := range checksums {
+		if checksum.Name == product && checksum.Version == version && checksum.Os == goos && checksum.Arch == goarch {
+			expectedChecksum = &checksum.Checksum
+			break
+		}
+	}
+	if expectedChecksum == nil {
+		return fmt.Errorf("%s %s for %s_%s %w", product, version, goos, goarch, errorutils.ErrNotFound)
+	}
+
+	path := h.storePath(product, version, zipFileName(product, version, goos, goarch))
+	return h.flights.do(path, func() error {
+		if _, err := os.Stat(path); err == nil {
+			return nil
+		}
+
+		var tempFileName string
+		var actualChecksum [32]byte
+		err := fetchFromMirrors(h.cmd, fmt.Sprintf("zip of %s %s for %s_%s", product, version, goos, goarch), func(mirror urlutils.Mirror) error {
+			zipURL, err := mirror.ZipURL(product, version, goos, goarch)
+			if err != nil {
+				return err
+			}
+
+			tempFileName, actualChecksum, err = downloadToTempFile(zipURL, progressutils.Discard)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		defer ioutils.Remove(tempFileName)
+
+		if actualChecksum != *expectedChecksum {
+			return fmt.Errorf("%w: zip of %s %s for %s_%s", errorutils.ErrChecksumMismatch, product, version, goos, goarch)
+		}
+
+		return copyStoreFile(path, tempFileName)
+	})
+}
+
+// serveFile serves name, the SHA256SUMS file, its signature or a zip of product's version, from the store.
+func (h *proxyHandler) serveFile(writer http.ResponseWriter, request *http.Request, product, version, name string) error {
+	sumsName := sumsFileName(product, version)
+	zipPrefix := product + "_" + version + "_"
+
+	var err error
+	switch {
+	case name == sumsName:
+		_, err = h.ensureChecksumList(product, version)
+	case name == sumsName+".sig":
+		err = h.ensureSignature(product, version)
+	case strings.HasPrefix(name, zipPrefix) && strings.HasSuffix(name, ".zip"):
+		platform := strings.SplitN(strings.TrimSuffix(strings.TrimPrefix(name, zipPrefix), ".zip"), "_", 2)
+		if len(platform) != 2 {
+			return fmt.Errorf("%s %w", name, errorutils.ErrNotFound)
+		}
+		err = h.ensureZip(product, version, platform[0], platform[1])
+	default:
+		return fmt.Errorf("%s %w", name, errorutils.ErrNotFound)
+	}
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(h.storePath(product, version, name))
+	if err != nil {
+		return err
+	}
+	defer ioutils.Close(file)
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	http.ServeContent(writer, request, name, info.ModTime(), file)
+	return nil
+}
+
+var proxyCmd = &cobra.Command{
+	Use:   "proxy",
+	Short: "Serve a pull-through cache of releases.hashicorp.com.",
+	Long: `Serve the URL space of releases.hashicorp.com as a pull-through cache.
+
+SHA256SUMS files and zips missing in --store are fetched from the mirrors on
+the first request, and stored after they are verified in the same way as
+installs: SHA256SUMS files with their signatures by the HashiCorp release key
+or --gpg-key, and zips with their checksums. Concurrent requests for the same
+file are fetched once. Listings are fetched again after --listing-ttl.
+
+Other hosts can use the cache with --mirror, e.g.
+
+  hashi install terraform 0.11.14 --mirror http://cache.example.com:8080`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store := proxyStore
+		if len(store) == 0 {
+			store = filepath.Join(cacheDir, "proxy")
+		}
+
+		if _, err := loadKeyRing(); err != nil {
+			return err
+		}
+
+		server := &http.Server{
+			Addr:    proxyAddr,
+			Handler: newProxyHandler(cmd, store, proxyListingTTL),
+		}
+
+		logf(cmd, verbosityInfo, "Serving a cache of %s in %s on %s", strings.Join(mirrorNames, ", "), store, proxyAddr)
+		return server.ListenAndServe()
+	},
+}
+
+func init() {
+	proxyCmd.Flags().StringVar(&proxyAddr, "addr", ":8080", "address to listen on")
+	proxyCmd.Flags().StringVar(&proxyStore, "store", "", "directory storing verified files (default <cache-dir>/proxy)")
+	proxyCmd.Flags().DurationVar(&proxyListingTTL, "listing-ttl", defaultListingTTL, "time to cache listings")
+}
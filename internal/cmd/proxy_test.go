@@ -0,0 +1,305 @@
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/porkbeans/hashi/internal/ioutils"
+
+	"github.com/porkbeans/hashi/pkg/parseutils"
+	"github.com/porkbeans/hashi/pkg/urlutils"
+	"golang.org/x/net/html"
+)
+
+// countingUpstream serves files of a flat mirror and counts requests by path.
+// Requests of zips wait until release is closed.
+type countingUpstream struct {
+	files   map[string][]byte
+	release chan struct{}
+
+	mutex  sync.Mutex
+	counts map[string]int
+}
+
+func (u *countingUpstream) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	u.mutex.Lock()
+	u.counts[request.URL.Path]++
+	u.mutex.Unlock()
+
+	if strings.HasSuffix(request.URL.Path, ".zip") {
+		<-u.release
+	}
+
+	content, ok := u.files[request.URL.Path]
+	if !ok {
+		writer.WriteHeader(404)
+		return
+	}
+	_, _ = writer.Write(content)
+}
+
+func (u *countingUpstream) count(path string) int {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+	return u.counts[path]
+}
+
+func setupProxy(t *testing.T, listingTTL time.Duration) (*countingUpstream, *httptest.Server, func()) {
+	mirrorServer, mirror, files := newFlatMirror(t, "terraform", []string{"0.11.14"})
+	mirrorServer.Close()
+
+	upstream := &countingUpstream{files: files, release: make(chan struct{}), counts: map[string]int{}}
+	upstreamServer := httptest.NewServer(upstream)
+	mirror.Base = upstreamServer.URL
+
+	originalMirrors := activeMirrors
+	activeMirrors = []urlutils.Mirror{mirror}
+
+	tempDir, err := ioutil.TempDir("", "hashi-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	originalKeyFile := gpgKeyFile
+	gpgKeyFile = writeFlatMirrorKey(t, tempDir)
+
+	proxyServer := httptest.NewServer(newProxyHandler(listCmd, tempDir, listingTTL))
+	listCmd.SetOutput(ioutil.Discard)
+
+	return upstream, proxyServer, func() {
+		proxyServer.Close()
+		upstreamServer.Close()
+		activeMirrors = originalMirrors
+		gpgKeyFile = originalKeyFile
+		listCmd.SetOutput(nil)
+		os.RemoveAll(tempDir)
+	}
+}
+
+func getProxy(t *testing.T, server *httptest.Server, path string) (int, []byte) {
+	resp, err := http.Get(server.URL + path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ioutils.Close(resp.Body)
+
+	content, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, content
+}
+
+func TestProxyListings(t *testing.T) {
+	upstream, server, teardown := setupProxy(t, time.Hour)
+	defer teardown()
+
+	status, content := getProxy(t, server, "/terraform/")
+	if status != 200 {
+		t.Fatalf("unexpected status %d", status)
+	}
+
+	node, err := html.Parse(bytes.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	baseURL, _ := url.Parse(server.URL + "/terraform/")
+	versions := parseutils.ParseLinkList(baseURL, node).ProductVersionList()
+	if len(versions) != 1 || versions[0].Version != "0.11.14" {
+		t.Errorf("unexpected versions %+v", versions)
+	}
+
+	getProxy(t, server, "/terraform/")
+	if count := upstream.count("/hashicorp/"); count != 1 {
+		t.Errorf("listing must be cached but fetched %d times", count)
+	}
+
+	status, content = getProxy(t, server, "/terraform/0.11.14/")
+	if status != 200 || !strings.Contains(string(content), "/terraform/0.11.14/terraform_0.11.14_linux_amd64.zip") {
+		t.Errorf("unexpected listing %d %s", status, content)
+	}
+
+	for path, expected := range map[string]int{
+		"/terraform/0.11.99/": 404,
+		"/terraform/0.11.14/terraform_0.11.14_darwin_amd64.zip": 404,
+		"/terraform/0.11.14/../../etc/passwd":                   404,
+		"/terraform/0.11.14/index.json":                         404,
+	} {
+		if status, _ := getProxy(t, server, path); status != expected {
+			t.Errorf("%s: expected %d but got %d", path, expected, status)
+		}
+	}
+
+	resp, err := http.Post(server.URL+"/terraform/", "text/plain", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ioutils.Close(resp.Body)
+	if resp.StatusCode != 405 {
+		t.Errorf("unexpected status %d", resp.StatusCode)
+	}
+}
+
+func TestProxyStaleListing(t *testing.T) {
+	upstream, server, teardown := setupProxy(t, 0)
+	defer teardown()
+
+	if status, _ := getProxy(t, server, "/terraform/"); status != 200 {
+		t.Fatalf("unexpected status %d", status)
+	}
+
+	delete(upstream.files, "/hashicorp/")
+	if status, content := getProxy(t, server, "/terraform/"); status != 200 || !strings.Contains(string(content), "0.11.14") {
+		t.Errorf("stale listing must be served: %d %s", status, content)
+	}
+	if count := upstream.count("/hashicorp/"); count != 2 {
+		t.Errorf("listing must be fetched again after the TTL but fetched %d times", count)
+	}
+}
+
+func TestProxyZip(t *testing.T) {
+	upstream, server, teardown := setupProxy(t, time.Hour)
+	defer teardown()
+
+	zipPath := "/hashicorp/terraform-0.11.14-linux-amd64.zip"
+	proxyPath := "/terraform/0.11.14/terraform_0.11.14_linux_amd64.zip"
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if status, content := getProxy(t, server, proxyPath); status != 200 || !bytes.Equal(content, upstream.files[zipPath]) {
+				t.Errorf("unexpected response %d", status)
+			}
+		}()
+	}
+
+	for upstream.count(zipPath) == 0 {
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(100 * time.Millisecond)
+	close(upstream.release)
+	wg.Wait()
+
+	if count := upstream.count(zipPath); count != 1 {
+		t.Errorf("concurrent requests must be coalesced but fetched %d times", count)
+	}
+
+	if status, content := getProxy(t, server, "/terraform/0.11.14/terraform_0.11.14_SHA256SUMS"); status != 200 || !bytes.Equal(content, upstream.files["/hashicorp/terraform_0.11.14_SHA256SUMS"]) {
+		t.Errorf("unexpected SHA256SUMS %d %s", status, content)
+	}
+}
+
+func TestProxyChecksumMismatch(t *testing.T) {
+	upstream, server, teardown := setupProxy(t, time.Hour)
+	defer teardown()
+	close(upstream.release)
+
+	upstream.files["/hashicorp/terraform-0.11.14-linux-amd64.zip"] = []byte("tampered")
+	proxyPath := "/terraform/0.11.14/terraform_0.11.14_linux_amd64.zip"
+	if status, _ := getProxy(t, server, proxyPath); status != 502 {
+		t.Errorf("unexpected status %d", status)
+	}
+
+	handler := server.Config.Handler.(*proxyHandler)
+	if _, err := os.Stat(filepath.Join(handler.store, "terraform", "0.11.14", "terraform_0.11.14_linux_amd64.zip")); err == nil {
+		t.Errorf("tampered zip must not be stored")
+	}
+}
+
+func TestProxySignature(t *testing.T) {
+	upstream, server, teardown := setupProxy(t, time.Hour)
+	defer teardown()
+	close(upstream.release)
+
+	handler := server.Config.Handler.(*proxyHandler)
+	sumsPath := filepath.Join(handler.store, "terraform", "0.11.14", "terraform_0.11.14_SHA256SUMS")
+	signature := upstream.files["/hashicorp/terraform_0.11.14_SHA256SUMS.sig"]
+
+	// Neither the SHA256SUMS file nor the signature is stored if the signature is made by an untrusted key.
+	upstream.files["/hashicorp/terraform_0.11.14_SHA256SUMS.sig"] = detachSign(t, newGPGEntity(t), upstream.files["/hashicorp/terraform_0.11.14_SHA256SUMS"])
+	for _, path := range []string{"/terraform/0.11.14/terraform_0.11.14_SHA256SUMS", "/terraform/0.11.14/terraform_0.11.14_SHA256SUMS.sig"} {
+		if status, _ := getProxy(t, server, path); status != 502 {
+			t.Errorf("%s: unexpected status %d", path, status)
+		}
+	}
+	for _, path := range []string{sumsPath, sumsPath + ".sig"} {
+		if _, err := os.Stat(path); err == nil {
+			t.Errorf("%s must not be stored", path)
+		}
+	}
+
+	// A SHA256SUMS file stored without its signature is fetched again with the signature.
+	upstream.files["/hashicorp/terraform_0.11.14_SHA256SUMS.sig"] = signature
+	if err := writeStoreFile(sumsPath, []byte("tampered")); err != nil {
+		t.Fatal(err)
+	}
+	if status, content := getProxy(t, server, "/terraform/0.11.14/terraform_0.11.14_SHA256SUMS.sig"); status != 200 || !bytes.Equal(content, signature) {
+		t.Errorf("unexpected signature %d", status)
+	}
+	if content, err := ioutil.ReadFile(sumsPath); err != nil || !bytes.Equal(content, upstream.files["/hashicorp/terraform_0.11.14_SHA256SUMS"]) {
+		t.Errorf("verified SHA256SUMS must be stored: %s", content)
+	}
+}
+
+func TestProxyCmdInvalidKey(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "hashi-test-")
+	if err != nil {
+		t.Fatalf("error should not happen")
+	}
+	defer os.RemoveAll(tempDir)
+
+	defer func(original string) { gpgKeyFile = original }(gpgKeyFile)
+	gpgKeyFile = filepath.Join(tempDir, "missing.asc")
+
+	if err := proxyCmd.RunE(proxyCmd, nil); err == nil {
+		t.Errorf("error must happen without a trusted key")
+	}
+}
+
+func TestFlightGroup(t *testing.T) {
+	group := flightGroup{}
+	started := make(chan struct{})
+	release := make(chan struct{})
+	calls := 0
+
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_ = group.do("key", func() error {
+			calls++
+			close(started)
+			<-release
+			return nil
+		})
+	}()
+
+	<-started
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_ = group.do("key", func() error {
+			calls++
+			return nil
+		})
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if calls != 1 {
+		t.Errorf("calls must be coalesced but called %d times", calls)
+	}
+}
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/porkbeans/hashi/internal/configutils"
+
+	"github.com/porkbeans/hashi/pkg/progressutils"
+	"github.com/porkbeans/hashi/pkg/urlutils"
+	"github.com/porkbeans/hashi/pkg/versionutils"
+	"github.com/spf13/cobra"
+)
+
+// listVersions returns all versions of product on the first mirror listing them.
+func listVersions(cmd *cobra.Command, product string) ([]string, error) {
+	var versions []string
+	err := fetchFromMirrors(cmd, "versions of "+product, func(mirror urlutils.Mirror) error {
+		var err error
+		versions, err = listMirrorVersions(mirror, product)
+		return err
+	})
+
+	return versions, err
+}
+
+// listMirrorVersions returns all versions of product on mirror.
+// Versions are taken from links to zips matching the zip template of the mirror,
+// or from links to version directories if there is no such link.
+func listMirrorVersions(mirror urlutils.Mirror, product string) ([]string, error) {
+	listingURL, err := mirror.ListingURL(product)
+	if err != nil {
+		return nil, err
+	}
+
+	pattern, err := mirror.ZipPattern(product)
+	if err != nil {
+		return nil, err
+	}
+
+	linkList, err := getList(nil, listingURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []string
+	found := map[string]bool{}
+	for _, entry := range linkList.MatchProductZipList(product, pattern) {
+		if !found[entry.Version] {
+			found[entry.Version] = true
+			versions = append(versions, entry.Version)
+		}
+	}
+	if len(versions) > 0 {
+		return versions, nil
+	}
+
+	for _, entry := range linkList.ProductVersionList() {
+		versions = append(versions, entry.Version)
+	}
+
+	return versions, nil
+}
+
+// resolveVersion resolves a version spec such as "auto", "latest" or "latest:^0.11" to a version of product.
+// Constraints are taken from required_version in .tf files of dir. Specific versions are returned as they are.
+func resolveVersion(cmd *cobra.Command, product, spec, dir string) (string, error) {
+	if versionutils.IsExact(spec) {
+		return versionutils.ResolveSpec(spec, nil, "")
+	}
+
+	var constraints []string
+	if versionutils.UsesConstraints(spec) && product == "terraform" {
+		var err error
+		if constraints, err = versionutils.TerraformRequiredVersions(dir); err != nil {
+			return "", err
+		}
+		logf(cmd, verbosityDebug, "Found required_version %s in %s", strings.Join(constraints, ", "), dir)
+	}
+
+	versions, err := listVersions(cmd, product)
+	if err != nil {
+		return "", err
+	}
+	logf(cmd, verbosityDebug, "Found %d versions of %s", len(versions), product)
+
+	resolved, err := versionutils.ResolveSpec(spec, versions, strings.Join(constraints, ", "))
+	if err != nil {
+		return "", err
+	}
+
+	message := fmt.Sprintf("Resolved %s %s to %s", product, spec, resolved)
+	if len(constraints) > 0 {
+		message += fmt.Sprintf(" (required_version %s)", strings.Join(constraints, ", "))
+	}
+	newObserver(cmd).Observe(progressutils.Event{
+		Type:    progressutils.EventResolve,
+		Product: product,
+		Version: resolved,
+		Message: message,
+	})
+	return resolved, nil
+}
+
+// findVersionSpec looks for the version spec of product used in dir.
+// It returns the spec and where it comes from.
+// Environment variables, .<product>-version files, .hashi.yaml and the config file are searched in order.
+func findVersionSpec(product, dir string) (string, string, error) {
+	envName := configutils.EnvName(product + "-version")
+	if spec, ok := os.LookupEnv(envName); ok && len(spec) > 0 {
+		return spec, envName, nil
+	}
+
+	spec, filename, found, err := versionutils.FindDotfile(dir, product)
+	if err != nil {
+		return "", "", err
+	}
+	if found {
+		return spec, filename, nil
+	}
+
+	if manifestPath, found := versionutils.FindUp(dir, versionutils.ManifestFileName); found {
+		manifest, err := versionutils.LoadManifest(manifestPath)
+		if err != nil {
+			return "", "", err
+		}
+		if spec, ok := manifest[product]; ok {
+			return spec, manifestPath, nil
+		}
+	}
+
+	if spec, ok := config.Get("versions." + product); ok {
+		return spec, configFile, nil
+	}
+
+	return "", "", errors.New("version of " + product + " is not specified")
+}
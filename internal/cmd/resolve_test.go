@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/porkbeans/hashi/pkg/versionutils"
+)
+
+func TestListVersions(t *testing.T) {
+	versions, err := listVersions(installCmd, "consul")
+	if err != nil {
+		t.Fatalf("error should not happen")
+	}
+
+	if len(versions) == 0 {
+		t.Errorf("versions must not be empty")
+	}
+}
+
+func TestResolveVersion(t *testing.T) {
+	version, err := resolveVersion(installCmd, "consul", "1.4.0", ".")
+	if err != nil || version != "1.4.0" {
+		t.Errorf("version must not be changed")
+	}
+}
+
+func TestResolveVersionAuto(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "hashi-test-")
+	if err != nil {
+		t.Fatalf("error should not happen")
+	}
+	defer os.RemoveAll(tempDir)
+
+	content := []byte("terraform {\n  required_version = \"~> 0.11.0\"\n}\n")
+	if err := ioutil.WriteFile(filepath.Join(tempDir, "main.tf"), content, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	version, err := resolveVersion(installCmd, "terraform", versionutils.Auto, tempDir)
+	if err != nil {
+		t.Fatalf("error should not happen")
+	}
+
+	if version != "0.11.15" {
+		t.Errorf("expected 0.11.15 but got %s", version)
+	}
+}
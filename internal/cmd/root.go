@@ -1,27 +1,168 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"runtime"
+	"time"
 
+	"github.com/porkbeans/hashi/internal/configutils"
+	"github.com/porkbeans/hashi/internal/stateutils"
+
+	"github.com/porkbeans/hashi/pkg/errorutils"
+	"github.com/porkbeans/hashi/pkg/progressutils"
+	"github.com/porkbeans/hashi/pkg/urlutils"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var (
+	configFile string
+	config     = configutils.New()
+
+	targetGOOS   string
+	targetGOARCH string
 )
 
+// bindFlags sets values of flags not given on the command line.
+// Values are taken from environment variables (HASHI_*), then from the config file.
+func bindFlags(flags *pflag.FlagSet, config *configutils.Config) error {
+	var err error
+	flags.VisitAll(func(flag *pflag.Flag) {
+		if err != nil || flag.Changed || flag.Name == "config" || flag.Name == "help" {
+			return
+		}
+
+		if value, ok := os.LookupEnv(configutils.EnvName(flag.Name)); ok {
+			if err = flag.Value.Set(value); err != nil {
+				err = fmt.Errorf("invalid %s: %s", configutils.EnvName(flag.Name), err)
+			}
+		} else if value, ok := config.Get(flag.Name); ok {
+			if err = flag.Value.Set(value); err != nil {
+				err = fmt.Errorf("invalid %s in %s: %s", flag.Name, configFile, err)
+			}
+		}
+	})
+
+	return err
+}
+
+func loadConfig(cmd *cobra.Command, args []string) error {
+	if !cmd.Flags().Changed("config") {
+		if value, ok := os.LookupEnv(configutils.EnvName("config")); ok {
+			configFile = value
+		}
+	}
+
+	var err error
+	if config, err = configutils.Load(configFile); err != nil {
+		return err
+	}
+
+	if err := bindFlags(cmd.Flags(), config); err != nil {
+		return err
+	}
+
+	if err := validateLogFormat(); err != nil {
+		return err
+	}
+	if offlineMode && refreshCache {
+		return errors.New("--refresh cannot be used with --offline")
+	}
+
+	mirrors, err := loadMirrors(mirrorNames)
+	if err != nil {
+		return err
+	}
+	activeMirrors = mirrors
+
+	return setupHTTPClient(cmd)
+}
+
 var rootCmd = cobra.Command{
 	Use:   "hashi",
 	Short: "Download and install HashiCorp tools.",
-	Long:  "Hashi is a tool for downloading and installing HashiCorp tools.",
+	Long: `Hashi is a tool for downloading and installing HashiCorp tools.
+
+Exit codes:
+  0  success
+  1  other errors
+  3  product, version or file not found
+  4  checksum mismatch
+  5  invalid signature
+  6  network error
+  7  platform unavailable
+  8  not cached, with --offline`,
+	PersistentPreRunE: loadConfig,
 }
 
 // Execute runs a hashi command.
 func Execute(args []string) int {
 	rootCmd.AddCommand(listCmd)
 	rootCmd.AddCommand(installCmd)
+	rootCmd.AddCommand(configCmd)
+	rootCmd.AddCommand(providerCmd)
+	rootCmd.AddCommand(serveCmd)
+	rootCmd.AddCommand(syncCmd)
+	rootCmd.AddCommand(useCmd)
+	rootCmd.AddCommand(shimsCmd)
+	rootCmd.AddCommand(shimCmd)
+	rootCmd.AddCommand(execCmd)
+	rootCmd.AddCommand(installedCmd)
+	rootCmd.AddCommand(uninstallCmd)
+	rootCmd.AddCommand(scanCmd)
+	rootCmd.AddCommand(adoptCmd)
+	rootCmd.AddCommand(proxyCmd)
 
 	rootCmd.SetArgs(args)
-	if err := rootCmd.Execute(); err != nil {
+	err := rootCmd.Execute()
+	if httpTracer != nil {
+		_ = writeTraceSummary(rootCmd.OutOrStderr(), httpTracer.Traces())
+	}
+
+	if err != nil {
+		if exitErr, ok := err.(exitCodeError); ok {
+			return exitErr.Code
+		}
+
+		code := errorutils.ExitCode(err)
+		if logFormat == logFormatJSON {
+			newObserver(&rootCmd).Observe(progressutils.Event{
+				Type:     progressutils.EventError,
+				ExitCode: code,
+				Message:  err.Error(),
+			})
+			return code
+		}
+
 		fmt.Fprintf(rootCmd.OutOrStderr(), "Err: %s\n", err)
-		return 1
+		return code
 	}
 
 	return 0
 }
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&configFile, "config", configutils.DefaultPath(), "config file")
+	rootCmd.PersistentFlags().StringVarP(&targetGOOS, "os", "o", runtime.GOOS, "operating system")
+	rootCmd.PersistentFlags().StringVarP(&targetGOARCH, "arch", "a", runtime.GOARCH, "architecture")
+	rootCmd.PersistentFlags().StringVar(&cacheDir, "cache-dir", defaultCacheDir(), "cache directory")
+	rootCmd.PersistentFlags().DurationVar(&cacheTTL, "cache-ttl", 5*time.Minute, "time to use cached listings and SHA256SUMS files before revalidating them")
+	rootCmd.PersistentFlags().BoolVar(&refreshCache, "refresh", false, "fetch listings and SHA256SUMS files ignoring the cache")
+	rootCmd.PersistentFlags().BoolVar(&keepZips, "keep-zips", false, "keep verified zips in <cache-dir>/zips for --offline and unreachable mirrors")
+	rootCmd.PersistentFlags().BoolVar(&offlineMode, "offline", false, "use only cached listings, SHA256SUMS files, signatures, zips and binaries without network access")
+	rootCmd.PersistentFlags().StringVar(&stateFile, "state-file", stateutils.DefaultPath(), "file recording installs")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", logFormatText, "format of progress and results, text or json")
+	rootCmd.PersistentFlags().IntVar(&progressStep, "progress-step", 10, "percentage between progress events unless drawn on a terminal")
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "print errors only")
+	rootCmd.PersistentFlags().CountVarP(&verbosity, "verbose", "v", "print details, or HTTP requests too with -vv")
+	rootCmd.PersistentFlags().BoolVar(&traceEnabled, "trace", false, "print timings of HTTP requests at the end")
+	rootCmd.PersistentFlags().StringVar(&transportOptions.CACert, "ca-cert", "", "PEM file of CA certificates trusted in addition to the system ones")
+	rootCmd.PersistentFlags().StringVar(&transportOptions.ClientCert, "client-cert", "", "PEM file of a client certificate for mTLS")
+	rootCmd.PersistentFlags().StringVar(&transportOptions.ClientKey, "client-key", "", "PEM file of the key of --client-cert")
+	rootCmd.PersistentFlags().BoolVar(&transportOptions.InsecureSkipVerify, "insecure-skip-verify", false, "do not verify TLS certificates (insecure)")
+	rootCmd.PersistentFlags().StringVar(&transportOptions.Proxy, "proxy", "", "proxy URL used instead of HTTPS_PROXY and HTTP_PROXY")
+	rootCmd.PersistentFlags().StringSliceVar(&mirrorNames, "mirror", []string{urlutils.DefaultMirror.Name}, "names of mirrors in the config file, or URLs of mirrors with the same layout as releases.hashicorp.com, tried in order")
+	rootCmd.PersistentFlags().StringVar(&gpgKeyFile, "gpg-key", "", "OpenPGP public key file verifying signatures of SHA256SUMS files (default the HashiCorp release key)")
+}
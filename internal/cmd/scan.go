@@ -0,0 +1,278 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/porkbeans/hashi/internal/stateutils"
+
+	"github.com/spf13/cobra"
+)
+
+// Statuses of scanned binaries.
+const (
+	scanManaged    = "managed"
+	scanVerified   = "verified"
+	scanUnverified = "unverified"
+	scanModified   = "modified"
+	scanUnknown    = "unknown"
+)
+
+const versionCommandTimeout = 10 * time.Second
+
+var (
+	scanDirs  []string
+	scanFetch bool
+
+	versionPattern = regexp.MustCompile(`v?(\d+\.\d+\.\d+(?:-[0-9A-Za-z.]+)?)`)
+)
+
+// scannedBinary represents a binary of product found on the host.
+type scannedBinary struct {
+	Product string
+	Version string
+	Path    string
+	Hash    string
+	Status  string
+}
+
+// defaultScanDirs returns directories where HashiCorp tools are commonly installed besides PATH.
+func defaultScanDirs() []string {
+	home := os.Getenv("HOME")
+	return []string{"/usr/local/bin", "/usr/bin", "/opt/bin", filepath.Join(home, "bin"), filepath.Join(home, ".local", "bin")}
+}
+
+// scanDirectories returns directories in PATH followed by dirs without duplicates.
+// Shims and the cache directory are excluded because they are managed by hashi.
+func scanDirectories(dirs []string) []string {
+	excluded := map[string]bool{
+		filepath.Clean(shimsDir): true,
+		filepath.Clean(cacheDir): true,
+	}
+
+	var result []string
+	for _, dir := range append(filepath.SplitList(os.Getenv("PATH")), dirs...) {
+		if len(dir) == 0 {
+			continue
+		}
+
+		dir = filepath.Clean(dir)
+		if excluded[dir] {
+			continue
+		}
+		excluded[dir] = true
+		result = append(result, dir)
+	}
+
+	return result
+}
+
+// findBinaries returns binaries of products in dirs.
+func findBinaries(products, dirs []string) []scannedBinary {
+	var binaries []scannedBinary
+	for _, dir := range dirs {
+		for _, product := range products {
+			path := filepath.Join(dir, binaryName(product, runtime.GOOS))
+			info, err := os.Stat(path)
+			if err != nil || !info.Mode().IsRegular() {
+				continue
+			}
+
+			binaries = append(binaries, scannedBinary{Product: product, Path: path})
+		}
+	}
+
+	return binaries
+}
+
+// binaryVersion runs "<binary> version" and returns the version in the first line of its output.
+func binaryVersion(path string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), versionCommandTimeout)
+	defer cancel()
+
+	command := exec.CommandContext(ctx, path, "version")
+	command.Env = append(os.Environ(), "CHECKPOINT_DISABLE=1")
+	output, err := command.Output()
+	if err != nil {
+		return "", err
+	}
+
+	line := strings.SplitN(string(output), "\n", 2)[0]
+	match := versionPattern.FindStringSubmatch(line)
+	if match == nil {
+		return "", fmt.Errorf("no version in the output of %s version", path)
+	}
+
+	return match[1], nil
+}
+
+// matchStoredBinary returns the version of product in the version store whose hash is hash.
+func matchStoredBinary(product, hash string) string {
+	pattern := filepath.Join(cacheDir, "versions", product, "*", runtime.GOOS+"_"+runtime.GOARCH, binaryName(product, runtime.GOOS))
+	matches, _ := filepath.Glob(pattern)
+	for _, match := range matches {
+		if storedHash, err := stateutils.HashFile(match); err == nil && storedHash == hash {
+			return filepath.Base(filepath.Dir(filepath.Dir(match)))
+		}
+	}
+
+	return ""
+}
+
+// identifyBinary sets the hash, the version and the status of binary.
+// The version reported by the binary is trusted only if its hash matches the published one.
+func identifyBinary(cmd *cobra.Command, binary *scannedBinary, state *stateutils.State) error {
+	hash, err := stateutils.HashFile(binary.Path)
+	if err != nil {
+		return err
+	}
+	binary.Hash = hash
+
+	for _, receipt := range state.Receipts {
+		if receipt.Path == binary.Path && receipt.BinaryHash == hash {
+			binary.Version = receipt.Version
+			binary.Status = scanManaged
+			return nil
+		}
+	}
+
+	if version := matchStoredBinary(binary.Product, hash); len(version) > 0 {
+		binary.Version = version
+		binary.Status = scanVerified
+		return nil
+	}
+
+	version, err := binaryVersion(binary.Path)
+	if err != nil {
+		binary.Status = scanUnknown
+		return nil
+	}
+	binary.Version = version
+	binary.Status = scanUnverified
+
+	if scanFetch {
+		if _, err := ensureStored(cmd, binary.Product, version, runtime.GOOS, runtime.GOARCH); err != nil {
+			logf(cmd, verbosityInfo, "Warning: failed to fetch %s %s: %s", binary.Product, version, err)
+			return nil
+		}
+
+		if matchStoredBinary(binary.Product, hash) == version {
+			binary.Status = scanVerified
+		} else {
+			binary.Status = scanModified
+		}
+	}
+
+	return nil
+}
+
+// scanBinaries finds and identifies binaries of products.
+func scanBinaries(cmd *cobra.Command, products []string) ([]scannedBinary, *stateutils.State, error) {
+	if len(products) == 0 {
+		products = defaultShimProducts
+	}
+
+	state, err := stateutils.Load(stateFile)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	binaries := findBinaries(products, scanDirectories(scanDirs))
+	for i := range binaries {
+		if err := identifyBinary(cmd, &binaries[i], state); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// The state is loaded again so that changes made while fetching with --fetch are not overwritten.
+	if state, err = stateutils.Load(stateFile); err != nil {
+		return nil, nil, err
+	}
+
+	return binaries, state, nil
+}
+
+var scanCmd = &cobra.Command{
+	Use:   "scan [name...]",
+	Short: "Find HashiCorp tools installed on this host.",
+	Long: `Find HashiCorp tools in PATH and --scan-dir, and identify their versions.
+
+A version is identified by the hash of a binary extracted from a published zip
+in the cache directory, or by running "<name> version". The status is one of:
+
+  managed     installed or adopted by hashi
+  verified    the hash matches the published binary
+  unverified  the version is reported by the binary but its hash is not checked
+  modified    the hash doesn't match the published binary of the reported version
+  unknown     the version cannot be identified
+
+Use --fetch to download published zips to the cache to check hashes.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		binaries, _, err := scanBinaries(cmd, args)
+		if err != nil {
+			return err
+		}
+
+		writer := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+		_, _ = fmt.Fprintln(writer, "PRODUCT\tVERSION\tSTATUS\tPATH")
+		for _, binary := range binaries {
+			_, _ = fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n", binary.Product, binary.Version, binary.Status, binary.Path)
+		}
+
+		return writer.Flush()
+	},
+}
+
+var adoptCmd = &cobra.Command{
+	Use:   "adopt [name...]",
+	Short: "Register HashiCorp tools installed by other means.",
+	Long: `Register HashiCorp tools found by "hashi scan" as installs managed by hashi.
+
+Binaries whose version is unknown or whose hash doesn't match the published one are skipped.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		binaries, state, err := scanBinaries(cmd, args)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now().UTC()
+		for _, binary := range binaries {
+			switch binary.Status {
+			case scanManaged:
+				continue
+			case scanUnknown, scanModified:
+				logf(cmd, verbosityInfo, "Skipped %s: %s", binary.Path, binary.Status)
+				continue
+			}
+
+			state.Add(stateutils.Receipt{
+				Product:     binary.Product,
+				Version:     binary.Version,
+				Os:          runtime.GOOS,
+				Arch:        runtime.GOARCH,
+				Path:        binary.Path,
+				BinaryHash:  binary.Hash,
+				InstalledAt: now,
+				Adopted:     true,
+			})
+			logf(cmd, verbosityInfo, "Adopted %s %s at %s (%s)", binary.Product, binary.Version, binary.Path, binary.Status)
+		}
+
+		return state.Save(stateFile)
+	},
+}
+
+func init() {
+	for _, command := range []*cobra.Command{scanCmd, adoptCmd} {
+		command.Flags().StringSliceVar(&scanDirs, "scan-dir", defaultScanDirs(), "directories to scan in addition to PATH")
+		command.Flags().BoolVar(&scanFetch, "fetch", false, "download published zips to the cache to check hashes")
+	}
+}
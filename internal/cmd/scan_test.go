@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/porkbeans/hashi/internal/stateutils"
+)
+
+func setupScan(t *testing.T) (string, func()) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake binaries are shell scripts")
+	}
+
+	tempDir, err := ioutil.TempDir("", "hashi-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	originalPath := os.Getenv("PATH")
+	originalStateFile, originalCacheDir, originalScanDirs := stateFile, cacheDir, scanDirs
+	binDir := filepath.Join(tempDir, "bin")
+	os.Setenv("PATH", binDir)
+	stateFile = filepath.Join(tempDir, "state.json")
+	cacheDir = filepath.Join(tempDir, "cache")
+	scanDirs = []string{filepath.Join(tempDir, "opt")}
+
+	writeFakeBinary(t, filepath.Join(binDir, "terraform"), "echo 'Terraform v0.11.14'")
+	writeFakeBinary(t, filepath.Join(tempDir, "opt", "vault"), "echo \"Vault v1.0.1 ('abc')\"")
+	writeFakeBinary(t, filepath.Join(binDir, "consul"), "exit 1")
+
+	return tempDir, func() {
+		os.Setenv("PATH", originalPath)
+		stateFile, cacheDir, scanDirs = originalStateFile, originalCacheDir, originalScanDirs
+		os.RemoveAll(tempDir)
+	}
+}
+
+func writeFakeBinary(t *testing.T, path, script string) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path, []byte("#!/bin/sh\n"+script+"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestScanDirectories(t *testing.T) {
+	defer os.Setenv("PATH", os.Getenv("PATH"))
+	defer func(originalShimsDir, originalCacheDir string) {
+		shimsDir, cacheDir = originalShimsDir, originalCacheDir
+	}(shimsDir, cacheDir)
+
+	shimsDir = "/home/user/.hashi/shims"
+	cacheDir = "/home/user/.cache/hashi"
+	os.Setenv("PATH", strings.Join([]string{"/home/user/.hashi/shims", "/usr/bin", "", "/bin/"}, string(filepath.ListSeparator)))
+
+	actual := scanDirectories([]string{"/usr/bin", "/opt/bin"})
+	expected := []string{"/usr/bin", "/bin", "/opt/bin"}
+	if strings.Join(actual, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected %v but got %v", expected, actual)
+	}
+}
+
+func TestBinaryVersion(t *testing.T) {
+	tempDir, teardown := setupScan(t)
+	defer teardown()
+
+	if version, err := binaryVersion(filepath.Join(tempDir, "opt", "vault")); err != nil || version != "1.0.1" {
+		t.Errorf("expected 1.0.1 but got %s, %v", version, err)
+	}
+	if _, err := binaryVersion(filepath.Join(tempDir, "bin", "consul")); err == nil {
+		t.Errorf("error should happen")
+	}
+}
+
+func TestScanCmd(t *testing.T) {
+	tempDir, teardown := setupScan(t)
+	defer teardown()
+
+	storedPath := storedBinaryPath("terraform", "0.11.13", runtime.GOOS, runtime.GOARCH)
+	writeFakeBinary(t, storedPath, "echo 'Terraform v0.11.14'")
+
+	binaries, _, err := scanBinaries(scanCmd, nil)
+	if err != nil {
+		t.Fatalf("error should not happen: %s", err)
+	}
+
+	expected := map[string]scannedBinary{
+		filepath.Join(tempDir, "bin", "consul"):    {Product: "consul", Status: scanUnknown},
+		filepath.Join(tempDir, "bin", "terraform"): {Product: "terraform", Version: "0.11.13", Status: scanVerified},
+		filepath.Join(tempDir, "opt", "vault"):     {Product: "vault", Version: "1.0.1", Status: scanUnverified},
+	}
+	if len(binaries) != len(expected) {
+		t.Fatalf("expected %d binaries but got %d", len(expected), len(binaries))
+	}
+	for _, binary := range binaries {
+		e := expected[binary.Path]
+		if binary.Product != e.Product || binary.Version != e.Version || binary.Status != e.Status {
+			t.Errorf("unexpected result %+v", binary)
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	scanCmd.SetOutput(buf)
+	defer scanCmd.SetOutput(nil)
+	if err := scanCmd.RunE(scanCmd, []string{"vault"}); err != nil {
+		t.Fatalf("error should not happen: %s", err)
+	}
+	if !strings.Contains(buf.String(), scanUnverified) || strings.Contains(buf.String(), "terraform") {
+		t.Errorf("unexpected output %s", buf.String())
+	}
+}
+
+func TestAdoptCmd(t *testing.T) {
+	tempDir, teardown := setupScan(t)
+	defer teardown()
+
+	adoptCmd.SetOutput(ioutil.Discard)
+	defer adoptCmd.SetOutput(nil)
+	if err := adoptCmd.RunE(adoptCmd, []string{}); err != nil {
+		t.Fatalf("error should not happen: %s", err)
+	}
+
+	state, err := stateutils.Load(stateFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(state.Receipts) != 2 {
+		t.Fatalf("expected 2 receipts but got %d", len(state.Receipts))
+	}
+	for _, receipt := range state.Receipts {
+		if !receipt.Adopted || receipt.Product == "consul" {
+			t.Errorf("unexpected receipt %+v", receipt)
+		}
+	}
+
+	binaries, _, err := scanBinaries(scanCmd, []string{"vault"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(binaries) != 1 || binaries[0].Status != scanManaged || binaries[0].Path != filepath.Join(tempDir, "opt", "vault") {
+		t.Errorf("vault must be managed")
+	}
+}
+
+func TestAdoptCmdKeepsReceiptsWrittenWhileScanning(t *testing.T) {
+	tempDir, teardown := setupScan(t)
+	defer teardown()
+
+	// The fake vault records an install while it is identified, as fetching with --fetch may do.
+	receipt := `{"receipts": [{"product": "packer", "version": "1.3.3", "path": "/usr/local/bin/packer"}]}`
+	writeFakeBinary(t, filepath.Join(tempDir, "opt", "vault"),
+		"echo '"+receipt+"' > '"+stateFile+"'\necho \"Vault v1.0.1 ('abc')\"")
+
+	adoptCmd.SetOutput(ioutil.Discard)
+	defer adoptCmd.SetOutput(nil)
+	if err := adoptCmd.RunE(adoptCmd, []string{"vault"}); err != nil {
+		t.Fatalf("error should not happen: %s", err)
+	}
+
+	state, err := stateutils.Load(stateFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(state.Receipts) != 2 || state.Receipts[0].Product != "packer" || state.Receipts[1].Product != "vault" {
+		t.Errorf("unexpected receipts %+v", state.Receipts)
+	}
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/porkbeans/hashi/pkg/providerutils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	serveAddr           string
+	serveProviderMirror bool
+	serveTLSCert        string
+	serveTLSKey         string
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve files populated by hashi.",
+	Long: `Serve files populated by hashi.
+
+With --provider-mirror, providers installed by "hashi provider install --packed"
+are served with the provider network mirror protocol of Terraform.
+Terraform requires HTTPS for network mirrors, so give --tls-cert and --tls-key
+or put the server behind a TLS terminating proxy.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !serveProviderMirror {
+			return errors.New("--provider-mirror is required")
+		}
+
+		server := &http.Server{
+			Addr:    serveAddr,
+			Handler: providerutils.NewMirrorHandler(providerPluginDir),
+		}
+
+		logf(cmd, verbosityInfo, "Serving provider mirror of %s on %s", providerPluginDir, serveAddr)
+		if len(serveTLSCert) > 0 || len(serveTLSKey) > 0 {
+			return server.ListenAndServeTLS(serveTLSCert, serveTLSKey)
+		}
+		return server.ListenAndServe()
+	},
+}
+
+func init() {
+	serveCmd.Flags().StringVar(&serveAddr, "addr", ":8080", "address to listen on")
+	serveCmd.Flags().BoolVar(&serveProviderMirror, "provider-mirror", false, "serve the provider network mirror protocol")
+	serveCmd.Flags().StringVar(&providerPluginDir, "plugin-dir", defaultPluginDir(), "plugin directory in the packed layout")
+	serveCmd.Flags().StringVar(&serveTLSCert, "tls-cert", "", "TLS certificate file")
+	serveCmd.Flags().StringVar(&serveTLSKey, "tls-key", "", "TLS private key file")
+}
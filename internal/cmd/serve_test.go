@@ -0,0 +1,9 @@
+package cmd
+
+import "testing"
+
+func TestServeCmdWithoutMode(t *testing.T) {
+	if err := serveCmd.RunE(serveCmd, nil); err == nil {
+		t.Errorf("error must happen")
+	}
+}
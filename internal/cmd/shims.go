@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	shimsDir string
+
+	defaultShimProducts = []string{"consul", "nomad", "packer", "terraform", "vault"}
+)
+
+// defaultShimsDir returns ~/.hashi/shims.
+func defaultShimsDir() string {
+	return filepath.Join(os.Getenv("HOME"), ".hashi", "shims")
+}
+
+// shimScript returns the file name and the content of a shim dispatching product to executable.
+func shimScript(executable, product, goos string) (string, string) {
+	if goos == "windows" {
+		return product + ".cmd", fmt.Sprintf("@echo off\r\n\"%s\" shim %s %%*\r\n", executable, product)
+	}
+
+	quoted := "'" + strings.Replace(executable, "'", `'\''`, -1) + "'"
+	return product, fmt.Sprintf("#!/bin/sh\n# Generated by hashi. Do not edit.\nexec %s shim %s \"$@\"\n", quoted, product)
+}
+
+var shimsInstallCmd = &cobra.Command{
+	Use:   "install [name...]",
+	Short: "Create shims of HashiCorp tools.",
+	Long: `Create shims of HashiCorp tools in --shims-dir.
+
+A shim runs the version of the tool selected for the current directory,
+installing it into the cache directory on demand. The version is taken from
+the first one found in:
+
+  1. HASHI_<NAME>_VERSION environment variable, e.g. HASHI_TERRAFORM_VERSION
+  2. .<name>-version in the current directory or its parents
+  3. .hashi.yaml in the current directory or its parents
+  4. versions.<name> in the config file, which is set by "hashi use"
+
+Add --shims-dir to the beginning of PATH to enable shims.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		products := args
+		if len(products) == 0 {
+			products = defaultShimProducts
+		}
+
+		executable, err := os.Executable()
+		if err != nil {
+			return err
+		}
+		if executable, err = filepath.Abs(executable); err != nil {
+			return err
+		}
+
+		if err := os.MkdirAll(shimsDir, os.FileMode(0755)); err != nil {
+			return err
+		}
+
+		for _, product := range products {
+			name, content := shimScript(executable, product, targetGOOS)
+			if err := ioutil.WriteFile(filepath.Join(shimsDir, name), []byte(content), os.FileMode(0755)); err != nil {
+				return err
+			}
+		}
+
+		logf(cmd, verbosityInfo, "Created shims of %s in %s", strings.Join(products, ", "), shimsDir)
+		logf(cmd, verbosityInfo, "Add %s to the beginning of PATH to use them", shimsDir)
+		return nil
+	},
+}
+
+var shimsCmd = &cobra.Command{
+	Use:   "shims",
+	Short: "Manage shims of HashiCorp tools.",
+}
+
+var shimCmd = &cobra.Command{
+	Use:                "shim <name> [args...]",
+	Short:              "Run the version of a HashiCorp tool selected for the current directory.",
+	Hidden:             true,
+	DisableFlagParsing: true,
+	SilenceErrors:      true,
+	SilenceUsage:       true,
+	Args:               cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return execProduct(cmd, args[0], "", args[1:])
+	},
+}
+
+func init() {
+	shimsCmd.PersistentFlags().StringVar(&shimsDir, "shims-dir", defaultShimsDir(), "directory of shims")
+
+	shimsCmd.AddCommand(shimsInstallCmd)
+}
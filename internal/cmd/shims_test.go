@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/porkbeans/hashi/internal/configutils"
+)
+
+func TestShimScript(t *testing.T) {
+	name, content := shimScript("/opt/it's/hashi", "terraform", "linux")
+	if name != "terraform" {
+		t.Errorf("unexpected name %s", name)
+	}
+	if !strings.Contains(content, `exec '/opt/it'\''s/hashi' shim terraform "$@"`) {
+		t.Errorf("unexpected content %s", content)
+	}
+
+	name, content = shimScript(`C:\hashi\hashi.exe`, "terraform", "windows")
+	if name != "terraform.cmd" {
+		t.Errorf("unexpected name %s", name)
+	}
+	if !strings.Contains(content, `"C:\hashi\hashi.exe" shim terraform %*`) {
+		t.Errorf("unexpected content %s", content)
+	}
+}
+
+func TestShimsInstallCmd(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "hashi-test-")
+	if err != nil {
+		t.Fatalf("error should not happen")
+	}
+	defer os.RemoveAll(tempDir)
+
+	defer func(original string) { shimsDir = original }(shimsDir)
+	shimsDir = filepath.Join(tempDir, "shims")
+
+	if err := shimsInstallCmd.RunE(shimsInstallCmd, []string{"terraform", "vault"}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, product := range []string{"terraform", "vault"} {
+		name, _ := shimScript("", product, targetGOOS)
+		if _, err := os.Stat(filepath.Join(shimsDir, name)); err != nil {
+			t.Errorf("shim of %s must be created", product)
+		}
+	}
+}
+
+func TestFindVersionSpec(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "hashi-test-")
+	if err != nil {
+		t.Fatalf("error should not happen")
+	}
+	defer os.RemoveAll(tempDir)
+
+	defer func(original *configutils.Config) { config = original }(config)
+	config = configutils.New()
+	_ = config.Set("versions.terraform", "0.11.14")
+	_ = config.Set("versions.vault", "1.0.1")
+	_ = config.Set("versions.consul", "1.3.0")
+	_ = config.Set("versions.nomad", "0.8.6")
+
+	files := map[string]string{
+		".terraform-version": "latest:^0.12",
+		".hashi.yaml":        "terraform: 0.12.0\nvault: 1.0.0\n",
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(tempDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	defer os.Unsetenv("HASHI_NOMAD_VERSION")
+	os.Setenv("HASHI_NOMAD_VERSION", "0.8.7")
+
+	expected := map[string]string{
+		"terraform": "latest:^0.12",
+		"vault":     "1.0.0",
+		"consul":    "1.3.0",
+		"nomad":     "0.8.7",
+	}
+	for product, expectedSpec := range expected {
+		spec, source, err := findVersionSpec(product, tempDir)
+		if err != nil {
+			t.Errorf("%s: %s", product, err)
+		}
+		if spec != expectedSpec {
+			t.Errorf("%s: expected %s from %s but got %s", product, expectedSpec, source, spec)
+		}
+	}
+
+	if _, _, err := findVersionSpec("packer", tempDir); err == nil {
+		t.Errorf("error must happen")
+	}
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/porkbeans/hashi/internal/httputils"
+
+	"github.com/porkbeans/hashi/pkg/progressutils"
+	"github.com/porkbeans/hashi/pkg/signatureutils"
+	"github.com/porkbeans/hashi/pkg/urlutils"
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/openpgp"
+)
+
+var gpgKeyFile string
+
+// loadKeyRing returns the keys trusted to sign SHA256SUMS files,
+// the ones in --gpg-key if it is given, otherwise the key HashiCorp signs releases with.
+func loadKeyRing() (openpgp.EntityList, error) {
+	if len(gpgKeyFile) == 0 {
+		return signatureutils.HashiCorpKeyRing()
+	}
+
+	return signatureutils.LoadKeyRing(gpgKeyFile)
+}
+
+// verifyChecksumList verifies the signature of content, the SHA256SUMS file of product's version, with the trusted keys,
+// and returns the signature. The signature may be served by any mirror because it is checked with the trusted keys only.
+func verifyChecksumList(cmd *cobra.Command, product, version string, content []byte) ([]byte, error) {
+	keyRing, err := loadKeyRing()
+	if err != nil {
+		return nil, err
+	}
+
+	var signature []byte
+	var displayURL string
+	err = fetchFromMirrors(cmd, fmt.Sprintf("signature of SHA256SUMS of %s %s", product, version), func(mirror urlutils.Mirror) error {
+		url, err := mirror.SignatureURL(product, version)
+		if err != nil {
+			return err
+		}
+
+		displayURL = httputils.RedactURL(url)
+		signature, err = fetchContent(url)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := signatureutils.Verify(keyRing, content, signature); err != nil {
+		return nil, fmt.Errorf("SHA256SUMS of %s %s: %w", product, version, err)
+	}
+
+	newObserver(cmd).Observe(progressutils.Event{
+		Type:    progressutils.EventSignatureVerified,
+		Product: product,
+		Version: version,
+		URL:     displayURL,
+		Message: "Signature Passed",
+	})
+	return signature, nil
+}
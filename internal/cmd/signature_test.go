@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/porkbeans/hashi/pkg/errorutils"
+	"github.com/porkbeans/hashi/pkg/urlutils"
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/armor"
+)
+
+func newGPGEntity(t *testing.T) *openpgp.Entity {
+	entity, err := openpgp.NewEntity("hashi", "test", "hashi@example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return entity
+}
+
+// writeGPGKey writes the armored public key of entity to filename.
+func writeGPGKey(t *testing.T, filename string, entity *openpgp.Entity) {
+	buf := &bytes.Buffer{}
+	writer, err := armor.Encode(buf, openpgp.PublicKeyType, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := entity.Serialize(writer); err != nil {
+		t.Fatal(err)
+	}
+	_ = writer.Close()
+
+	if err := ioutil.WriteFile(filename, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func detachSign(t *testing.T, entity *openpgp.Entity, content []byte) []byte {
+	signature := &bytes.Buffer{}
+	if err := openpgp.DetachSign(signature, entity, bytes.NewReader(content), nil); err != nil {
+		t.Fatal(err)
+	}
+	return signature.Bytes()
+}
+
+func TestVerifyChecksumList(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "hashi-test-")
+	if err != nil {
+		t.Fatalf("error should not happen")
+	}
+	defer os.RemoveAll(tempDir)
+
+	defer func(original string) { gpgKeyFile = original }(gpgKeyFile)
+	gpgKeyFile = filepath.Join(tempDir, "hashicorp.asc")
+	entity := newGPGEntity(t)
+	writeGPGKey(t, gpgKeyFile, entity)
+
+	primaryServer, primary, primaryFiles := newFlatMirror(t, "vault", []string{"1.0.1"})
+	defer primaryServer.Close()
+	secondaryServer, secondary, secondaryFiles := newFlatMirror(t, "vault", []string{"1.0.1"})
+	defer secondaryServer.Close()
+	primary.Name = "primary"
+	secondary.Name = "secondary"
+
+	defer func(original []urlutils.Mirror) { activeMirrors = original }(activeMirrors)
+	activeMirrors = []urlutils.Mirror{primary, secondary}
+
+	// The signature missing on primary is served by secondary.
+	sumsPath := "/hashicorp/vault_1.0.1_SHA256SUMS"
+	delete(primaryFiles, sumsPath+".sig")
+	secondaryFiles[sumsPath+".sig"] = detachSign(t, entity, primaryFiles[sumsPath])
+
+	buf := &bytes.Buffer{}
+	listCmd.SetOutput(buf)
+	defer listCmd.SetOutput(nil)
+
+	if _, err := getChecksumList(listCmd, "vault", "1.0.1"); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{"Fetched signature of SHA256SUMS of vault 1.0.1 from secondary", "Signature Passed"} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("%q must be logged: %s", expected, buf.String())
+		}
+	}
+
+	// SHA256SUMS not signed by the key is rejected wherever the signature comes from.
+	primaryFiles[sumsPath] = append(primaryFiles[sumsPath], "0000  vault_1.0.1_darwin_amd64.zip\n"...)
+	_, err = getChecksumList(listCmd, "vault", "1.0.1")
+	if !errors.Is(err, errorutils.ErrSignatureInvalid) {
+		t.Errorf("invalid signature error must happen: %v", err)
+	}
+	if code := errorutils.ExitCode(err); code != errorutils.ExitSignatureInvalid {
+		t.Errorf("unexpected exit code %d", code)
+	}
+
+	delete(secondaryFiles, sumsPath+".sig")
+	if _, err := getChecksumList(listCmd, "vault", "1.0.1"); !errors.Is(err, errorutils.ErrNotFound) {
+		t.Errorf("missing signature must not be ignored: %v", err)
+	}
+}
@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/porkbeans/hashi/internal/ioutils"
+
+	"github.com/porkbeans/hashi/pkg/errorutils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	cacheDir     string
+	cacheTTL     time.Duration
+	refreshCache bool
+	offlineMode  bool
+	keepZips     bool
+)
+
+// defaultCacheDir returns $XDG_CACHE_HOME/hashi, or ~/.cache/hashi if XDG_CACHE_HOME is not set.
+func defaultCacheDir() string {
+	cacheHome := os.Getenv("XDG_CACHE_HOME")
+	if len(cacheHome) == 0 {
+		cacheHome = filepath.Join(os.Getenv("HOME"), ".cache")
+	}
+
+	return filepath.Join(cacheHome, "hashi")
+}
+
+// httpCacheDir returns a directory caching listings, SHA256SUMS files and signatures.
+func httpCacheDir() string {
+	return filepath.Join(cacheDir, "http")
+}
+
+// isCacheableRequest reports whether the response of request is cached in httpCacheDir.
+// Zips are not, because binaries are kept in the version store instead.
+func isCacheableRequest(request *http.Request) bool {
+	return !strings.HasSuffix(request.URL.Path, ".zip")
+}
+
+// cachedZipPath returns a path of the verified zip of product kept in the cache directory for offline installs.
+func cachedZipPath(product, version, goos, goarch string) string {
+	return filepath.Join(cacheDir, "zips", product, version, fmt.Sprintf("%s_%s_%s_%s.zip", product, version, goos, goarch))
+}
+
+// keepZip copies the verified zip of product at src to the cache directory. Failures are only logged with -v.
+func keepZip(cmd *cobra.Command, src, product, version, goos, goarch string) {
+	dst := cachedZipPath(product, version, goos, goarch)
+	newPath := dst + ".hashi-new"
+
+	err := ioutils.CopyFile(newPath, src, os.FileMode(0644))
+	if err == nil {
+		err = os.Rename(newPath, dst)
+	}
+	if err != nil {
+		ioutils.Remove(newPath)
+		logf(cmd, verbosityDetail, "Failed to cache %s: %s", dst, err)
+	}
+}
+
+// copyCachedZip copies the zip of product kept in the cache directory to a temporary file, and returns its checksum.
+func copyCachedZip(product, version, goos, goarch string) (string, [32]byte, error) {
+	checksum := [32]byte{}
+
+	src, err := os.Open(cachedZipPath(product, version, goos, goarch))
+	if os.IsNotExist(err) {
+		return "", checksum, &errorutils.NotCachedError{Artifact: fmt.Sprintf("zip of %s %s for %s_%s", product, version, goos, goarch)}
+	} else if err != nil {
+		return "", checksum, err
+	}
+	defer ioutils.Close(src)
+
+	tempFile, err := ioutil.TempFile("", "hashi-")
+	if err != nil {
+		return "", checksum, err
+	}
+	defer ioutils.Close(tempFile)
+
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(tempFile, hash), src); err != nil {
+		ioutils.Remove(tempFile.Name())
+		return "", checksum, err
+	}
+	copy(checksum[:], hash.Sum(nil))
+
+	return tempFile.Name(), checksum, nil
+}
+
+// storedBinaryPath returns a path of product's binary in the version store under the cache directory.
+func storedBinaryPath(product, version, goos, goarch string) string {
+	return filepath.Join(cacheDir, "versions", product, version, goos+"_"+goarch, binaryName(product, goos))
+}
+
+// ensureStored installs product into the version store unless it is already there.
+func ensureStored(cmd *cobra.Command, product, version, goos, goarch string) (string, error) {
+	binaryPath := storedBinaryPath(product, version, goos, goarch)
+	if _, err := os.Stat(binaryPath); err == nil {
+		logf(cmd, verbosityDetail, "Found %s %s in the cache at %s", product, version, binaryPath)
+		return binaryPath, nil
+	}
+	logf(cmd, verbosityDetail, "%s %s is not in the cache at %s", product, version, binaryPath)
+
+	if err := os.MkdirAll(filepath.Dir(binaryPath), os.FileMode(0755)); err != nil {
+		return "", err
+	}
+
+	if _, err := installBinary(cmd, product, version, goos, goarch, binaryPath); err != nil {
+		ioutils.Remove(binaryPath)
+		return "", err
+	}
+
+	return binaryPath, nil
+}
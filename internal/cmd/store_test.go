@@ -0,0 +1,221 @@
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/porkbeans/hashi/internal/httputils"
+	"github.com/porkbeans/hashi/internal/ioutils"
+	"github.com/porkbeans/hashi/internal/stateutils"
+
+	"github.com/porkbeans/hashi/pkg/errorutils"
+	"github.com/porkbeans/hashi/pkg/urlutils"
+)
+
+func TestStoredBinaryPath(t *testing.T) {
+	defer func(original string) { cacheDir = original }(cacheDir)
+	cacheDir = "cache"
+
+	expected := filepath.Join("cache", "versions", "terraform", "0.11.14", "windows_amd64", "terraform.exe")
+	if actual := storedBinaryPath("terraform", "0.11.14", "windows", "amd64"); actual != expected {
+		t.Errorf("expected %s but got %s", expected, actual)
+	}
+}
+
+func TestEnsureStored(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "hashi-test-")
+	if err != nil {
+		t.Fatalf("error should not happen")
+	}
+	defer os.RemoveAll(tempDir)
+
+	defer func(original string) { cacheDir = original }(cacheDir)
+	cacheDir = tempDir
+
+	binaryPath, err := ensureStored(installCmd, "consul", "1.4.0", targetGOOS, targetGOARCH)
+	if err != nil {
+		t.Fatalf("error should not happen")
+	}
+
+	if _, err := os.Stat(binaryPath); err != nil {
+		t.Errorf("consul must be stored")
+	}
+}
+
+func TestEnsureStoredNotFound(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "hashi-test-")
+	if err != nil {
+		t.Fatalf("error should not happen")
+	}
+	defer os.RemoveAll(tempDir)
+
+	defer func(original string) { cacheDir = original }(cacheDir)
+	cacheDir = tempDir
+
+	binaryPath, err := ensureStored(installCmd, "unknown", "1.4.0", targetGOOS, targetGOARCH)
+	if err == nil {
+		t.Errorf("error must happen")
+	}
+
+	if _, err := os.Stat(storedBinaryPath("unknown", "1.4.0", targetGOOS, targetGOARCH)); err == nil {
+		t.Errorf("%s must be removed", binaryPath)
+	}
+}
+
+func TestHTTPCache(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "hashi-test-")
+	if err != nil {
+		t.Fatalf("error should not happen")
+	}
+	defer os.RemoveAll(tempDir)
+
+	requests := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		requests[request.URL.Path]++
+		writer.Header().Set("ETag", `"listing"`)
+		_, _ = writer.Write([]byte(`<ul><li><a href="/vault/1.0.1/">vault_1.0.1</a></li></ul>`))
+	}))
+	defer server.Close()
+
+	defer func(originalDir string, originalTTL time.Duration, originalClient httputils.HTTPGetClient) {
+		cacheDir, cacheTTL, refreshCache, httputils.DefaultClient = originalDir, originalTTL, false, originalClient
+	}(cacheDir, cacheTTL, httputils.DefaultClient)
+	cacheDir, cacheTTL = tempDir, time.Hour
+
+	testCases := []struct {
+		refresh  bool
+		path     string
+		expected int
+	}{
+		{false, "/vault/", 1},
+		{false, "/vault/", 1},
+		{true, "/vault/", 2},
+		{false, "/vault/1.0.1/vault_1.0.1_linux_amd64.zip", 1},
+		{false, "/vault/1.0.1/vault_1.0.1_linux_amd64.zip", 2},
+	}
+
+	for _, testCase := range testCases {
+		refreshCache = testCase.refresh
+		if err := setupHTTPClient(listCmd); err != nil {
+			t.Fatalf("error should not happen: %s", err)
+		}
+
+		if _, err := getList(nil, server.URL+testCase.path); err != nil {
+			t.Fatalf("error should not happen: %s", err)
+		}
+		if requests[testCase.path] != testCase.expected {
+			t.Errorf("expected %d requests of %s but got %d", testCase.expected, testCase.path, requests[testCase.path])
+		}
+	}
+}
+
+func TestOffline(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "hashi-test-")
+	if err != nil {
+		t.Fatalf("error should not happen")
+	}
+	defer os.RemoveAll(tempDir)
+
+	defer func(original string) { gpgKeyFile = original }(gpgKeyFile)
+	gpgKeyFile = writeFlatMirrorKey(t, tempDir)
+
+	server, mirror, _ := newFlatMirror(t, "terraform", []string{"0.11.14"})
+	defer server.Close()
+
+	defer func(originalDir string, originalTTL time.Duration, originalMirrors []urlutils.Mirror, originalClient httputils.HTTPGetClient) {
+		cacheDir, cacheTTL, activeMirrors, offlineMode, keepZips, httputils.DefaultClient = originalDir, originalTTL, originalMirrors, false, false, originalClient
+	}(cacheDir, cacheTTL, activeMirrors, httputils.DefaultClient)
+	cacheDir, cacheTTL, activeMirrors = tempDir, 0, []urlutils.Mirror{mirror}
+
+	buf := &bytes.Buffer{}
+	listCmd.SetOutput(buf)
+	defer listCmd.SetOutput(nil)
+
+	if err := setupHTTPClient(listCmd); err != nil {
+		t.Fatalf("error should not happen: %s", err)
+	}
+	if _, err := listVersions(listCmd, "terraform"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Zips are kept only with --keep-zips.
+	for _, keep := range []bool{false, true} {
+		keepZips = keep
+		tempFile, _, err := downloadVerifiedZip(listCmd, "terraform", "0.11.14", "linux", "amd64")
+		if err != nil {
+			t.Fatal(err)
+		}
+		ioutils.Remove(tempFile)
+
+		if _, err := os.Stat(cachedZipPath("terraform", "0.11.14", "linux", "amd64")); (err == nil) != keep {
+			t.Errorf("zip must be kept only with --keep-zips: %v", err)
+		}
+	}
+	server.Close()
+
+	// The zip is taken from the cache when the mirror is unreachable, and with --offline.
+	for _, offline := range []bool{false, true} {
+		offlineMode = offline
+		if err := setupHTTPClient(listCmd); err != nil {
+			t.Fatalf("error should not happen: %s", err)
+		}
+
+		buf.Reset()
+		if versions, err := listVersions(listCmd, "terraform"); err != nil || !reflect.DeepEqual(versions, []string{"0.11.14"}) {
+			t.Errorf("unexpected versions %v: %v", versions, err)
+		}
+		tempFile, _, err := downloadVerifiedZip(listCmd, "terraform", "0.11.14", "linux", "amd64")
+		if err != nil {
+			t.Fatal(err)
+		}
+		ioutils.Remove(tempFile)
+
+		if !strings.Contains(buf.String(), "may be stale") {
+			t.Errorf("stale data must be warned: %s", buf.String())
+		}
+	}
+
+	_, _, err = downloadVerifiedZip(listCmd, "terraform", "0.11.13", "linux", "amd64")
+	if code := errorutils.ExitCode(err); code != errorutils.ExitNotCached {
+		t.Errorf("not cached error must happen: %v", err)
+	}
+}
+
+func TestEnsureStoredWithoutReceipt(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "hashi-test-")
+	if err != nil {
+		t.Fatalf("error should not happen")
+	}
+	defer os.RemoveAll(tempDir)
+
+	defer func(original string) { gpgKeyFile = original }(gpgKeyFile)
+	gpgKeyFile = writeFlatMirrorKey(t, tempDir)
+
+	server, mirror, _ := newFlatMirror(t, "terraform", []string{"0.11.14"})
+	defer server.Close()
+
+	defer func(originalDir, originalStateFile string, originalMirrors []urlutils.Mirror) {
+		cacheDir, stateFile, activeMirrors = originalDir, originalStateFile, originalMirrors
+	}(cacheDir, stateFile, activeMirrors)
+	cacheDir, stateFile, activeMirrors = tempDir, filepath.Join(tempDir, "state.json"), []urlutils.Mirror{mirror}
+
+	if _, err := ensureStored(listCmd, "terraform", "0.11.14", "linux", "amd64"); err != nil {
+		t.Fatal(err)
+	}
+
+	state, err := stateutils.Load(stateFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(state.Receipts) != 0 {
+		t.Errorf("binaries in the version store must not be recorded: %+v", state.Receipts)
+	}
+}
@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/porkbeans/hashi/pkg/errorutils"
+	"github.com/porkbeans/hashi/pkg/urlutils"
+	"github.com/porkbeans/hashi/pkg/versionutils"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// maxSuggestions is the maximum number of alternatives shown in errors.
+	maxSuggestions = 5
+)
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			current[j] = previous[j-1] + cost
+			if previous[j]+1 < current[j] {
+				current[j] = previous[j] + 1
+			}
+			if current[j-1]+1 < current[j] {
+				current[j] = current[j-1] + 1
+			}
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(rb)]
+}
+
+// similarNames returns names close to target by edit distance, the closest first.
+func similarNames(names []string, target string, n int) []string {
+	threshold := len(target) / 3
+	if threshold < 2 {
+		threshold = 2
+	}
+
+	distances := map[string]int{}
+	var similar []string
+	for _, name := range names {
+		distance := editDistance(strings.ToLower(name), strings.ToLower(target))
+		if distance <= threshold || strings.HasPrefix(strings.ToLower(name), strings.ToLower(target)) {
+			distances[name] = distance
+			similar = append(similar, name)
+		}
+	}
+
+	sort.SliceStable(similar, func(i, j int) bool {
+		return distances[similar[i]] < distances[similar[j]]
+	})
+	if len(similar) > n {
+		similar = similar[:n]
+	}
+
+	return similar
+}
+
+// missingReleaseError explains which of product and version is not published, with similar products or nearby versions.
+// err is returned as it is if both exist or the releases site cannot be listed.
+// Similar products are suggested only for the releases site because mirrors may not list products.
+func missingReleaseError(cmd *cobra.Command, product, version string, err error) error {
+	var products []string
+	if usesReleasesOnly() {
+		linkList, listErr := getList(nil, urlutils.HashicorpProductList)
+		if listErr != nil {
+			return err
+		}
+
+		for _, link := range linkList {
+			if link.Name == product {
+				products = nil
+				break
+			}
+			products = append(products, link.Name)
+		}
+	}
+
+	if products != nil {
+		if similar := similarNames(products, product, maxSuggestions); len(similar) > 0 {
+			return fmt.Errorf("product %s %w, did you mean %s?", product, errorutils.ErrNotFound, strings.Join(similar, ", "))
+		}
+		return fmt.Errorf("product %s %w, run \"hashi list\" to see products", product, errorutils.ErrNotFound)
+	}
+
+	versions, listErr := listVersions(cmd, product)
+	if listErr != nil {
+		return err
+	}
+	for _, v := range versions {
+		if v == version {
+			return err
+		}
+	}
+
+	return fmt.Errorf("%s %s %w, nearby versions: %s", product, version, errorutils.ErrNotFound, strings.Join(versionutils.Nearby(versions, version, maxSuggestions), ", "))
+}
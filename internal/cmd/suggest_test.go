@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEditDistance(t *testing.T) {
+	testCases := []struct {
+		a, b     string
+		expected int
+	}{
+		{"", "", 0},
+		{"vault", "vault", 0},
+		{"terraform", "terrafrom", 2},
+		{"consul", "consol", 1},
+		{"nomad", "", 5},
+		{"packer", "pack", 2},
+	}
+
+	for _, testCase := range testCases {
+		if actual := editDistance(testCase.a, testCase.b); actual != testCase.expected {
+			t.Errorf("%s, %s: expected %d but got %d", testCase.a, testCase.b, testCase.expected, actual)
+		}
+	}
+}
+
+func TestSimilarNames(t *testing.T) {
+	products := []string{"consul", "nomad", "packer", "terraform", "terraform-provider-aws", "vault"}
+
+	testCases := []struct {
+		target   string
+		expected []string
+	}{
+		{"terrafrom", []string{"terraform"}},
+		{"vaul", []string{"vault"}},
+		{"terraform-provider", []string{"terraform-provider-aws"}},
+		{"Terra", []string{"terraform", "terraform-provider-aws"}},
+		{"kubernetes", nil},
+	}
+
+	for _, testCase := range testCases {
+		actual := similarNames(products, testCase.target, maxSuggestions)
+		if strings.Join(actual, ",") != strings.Join(testCase.expected, ",") {
+			t.Errorf("%s: expected %v but got %v", testCase.target, testCase.expected, actual)
+		}
+	}
+}
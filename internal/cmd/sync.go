@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/porkbeans/hashi/pkg/versionutils"
+	"github.com/spf13/cobra"
+)
+
+var syncDir string
+
+// loadSyncManifest returns .hashi.yaml found from dir to the root.
+// Without it, terraform is synced if required_version is declared in .tf files of dir.
+func loadSyncManifest(dir string) (versionutils.Manifest, error) {
+	if manifestPath, found := versionutils.FindUp(dir, versionutils.ManifestFileName); found {
+		return versionutils.LoadManifest(manifestPath)
+	}
+
+	constraints, err := versionutils.TerraformRequiredVersions(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(constraints) == 0 {
+		return nil, fmt.Errorf("neither %s nor required_version in .tf files is found", versionutils.ManifestFileName)
+	}
+
+	return versionutils.Manifest{"terraform": versionutils.Auto}, nil
+}
+
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Install tools listed in .hashi.yaml, or terraform required by .tf files.",
+	Long: `Install tools listed in .hashi.yaml into --dir.
+
+.hashi.yaml is looked up from the current directory to the root, and maps
+products to versions. A version "auto" is resolved in the same way as install.
+
+  terraform: auto
+  vault: 1.0.1
+
+Without .hashi.yaml, terraform satisfying required_version in .tf files of
+the current directory is installed.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(syncDir) == 0 {
+			return errors.New("--dir is required")
+		}
+
+		manifest, err := loadSyncManifest(".")
+		if err != nil {
+			return err
+		}
+
+		for _, product := range manifest.Products() {
+			version, err := resolveVersion(cmd, product, manifest[product], ".")
+			if err != nil {
+				return err
+			}
+
+			installPath := filepath.Join(syncDir, binaryName(product, targetGOOS))
+			zipChecksum, err := installBinary(cmd, product, version, targetGOOS, targetGOARCH, installPath)
+			if err != nil {
+				return err
+			}
+			recordInstall(cmd, product, version, targetGOOS, targetGOARCH, zipChecksum, []string{installPath})
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	syncCmd.Flags().StringVar(&syncDir, "dir", "", "destination directory")
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/porkbeans/hashi/pkg/versionutils"
+)
+
+func TestSyncCmd(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "hashi-test-")
+	if err != nil {
+		t.Fatalf("error should not happen")
+	}
+	defer os.RemoveAll(tempDir)
+
+	if err := ioutil.WriteFile(filepath.Join(tempDir, ".hashi.yaml"), []byte("consul: 1.4.0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	wd, _ := os.Getwd()
+	defer os.Chdir(wd)
+	if err := os.Chdir(tempDir); err != nil {
+		t.Fatal(err)
+	}
+
+	defer func(original string) { syncDir = original }(syncDir)
+	syncDir = tempDir
+
+	if err := syncCmd.RunE(syncCmd, nil); err != nil {
+		t.Fatalf("error should not happen")
+	}
+
+	if _, err := os.Stat(filepath.Join(tempDir, binaryName("consul", targetGOOS))); err != nil {
+		t.Errorf("consul must be installed")
+	}
+}
+
+func TestSyncCmdWithoutDir(t *testing.T) {
+	defer func(original string) { syncDir = original }(syncDir)
+	syncDir = ""
+
+	if err := syncCmd.RunE(syncCmd, nil); err == nil {
+		t.Errorf("error must happen")
+	}
+}
+
+func TestLoadSyncManifest(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "hashi-test-")
+	if err != nil {
+		t.Fatalf("error should not happen")
+	}
+	defer os.RemoveAll(tempDir)
+
+	if _, err := loadSyncManifest(tempDir); err == nil {
+		t.Errorf("error must happen without .hashi.yaml and required_version")
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(tempDir, "versions.tf"), []byte("terraform {\n  required_version = \"~> 0.11.0\"\n}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	manifest, err := loadSyncManifest(tempDir)
+	if err != nil {
+		t.Fatalf("error should not happen: %s", err)
+	}
+	if expected := (versionutils.Manifest{"terraform": "auto"}); !reflect.DeepEqual(manifest, expected) {
+		t.Errorf("expected %v but got %v", expected, manifest)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(tempDir, ".hashi.yaml"), []byte("vault: 1.0.1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	manifest, err = loadSyncManifest(tempDir)
+	if err != nil {
+		t.Fatalf("error should not happen: %s", err)
+	}
+	if expected := (versionutils.Manifest{"vault": "1.0.1"}); !reflect.DeepEqual(manifest, expected) {
+		t.Errorf("expected %v but got %v", expected, manifest)
+	}
+}
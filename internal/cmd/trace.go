@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/porkbeans/hashi/internal/httputils"
+)
+
+var (
+	traceEnabled bool
+	httpTracer   *httputils.TracingClient
+)
+
+func formatBytes(n float64) string {
+	units := []string{"B", "kB", "MB", "GB"}
+	unit := 0
+	for n >= 1000 && unit < len(units)-1 {
+		n /= 1000
+		unit++
+	}
+	return fmt.Sprintf("%.3g %s", n, units[unit])
+}
+
+func formatDuration(d time.Duration) string {
+	if d <= 0 {
+		return "-"
+	}
+	return d.Round(time.Millisecond).String()
+}
+
+// writeTraceSummary writes a table of timings of traced requests.
+func writeTraceSummary(writer io.Writer, traces []*httputils.Trace) error {
+	table := tabwriter.NewWriter(writer, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(table, "URL\tDNS\tCONNECT\tTLS\tTTFB\tTRANSFER\tBYTES\tRATE")
+	for _, trace := range traces {
+		_, _ = fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%s\t%s\t%d\t%s/s\n",
+			trace.URL,
+			formatDuration(trace.DNS),
+			formatDuration(trace.Connect),
+			formatDuration(trace.TLS),
+			formatDuration(trace.TTFB),
+			formatDuration(trace.Transfer),
+			trace.Bytes,
+			formatBytes(trace.BytesPerSecond()),
+		)
+	}
+
+	return table.Flush()
+}
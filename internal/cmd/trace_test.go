@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/porkbeans/hashi/internal/httputils"
+)
+
+func TestFormatBytes(t *testing.T) {
+	testCases := map[float64]string{
+		0:       "0 B",
+		999:     "999 B",
+		1500:    "1.5 kB",
+		2500000: "2.5 MB",
+	}
+
+	for n, expected := range testCases {
+		if actual := formatBytes(n); actual != expected {
+			t.Errorf("expected %s but got %s", expected, actual)
+		}
+	}
+}
+
+func TestWriteTraceSummary(t *testing.T) {
+	buf := new(bytes.Buffer)
+	traces := []*httputils.Trace{
+		{
+			URL:      "https://releases.hashicorp.com/vault/1.0.1/vault_1.0.1_linux_amd64.zip",
+			DNS:      12 * time.Millisecond,
+			Connect:  30 * time.Millisecond,
+			TLS:      45 * time.Millisecond,
+			TTFB:     120 * time.Millisecond,
+			Transfer: 2 * time.Second,
+			Bytes:    30000000,
+		},
+		{URL: "https://releases.hashicorp.com/vault/1.0.1/vault_1.0.1_SHA256SUMS"},
+	}
+
+	if err := writeTraceSummary(buf, traces); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "URL") {
+		t.Fatalf("unexpected table %q", buf.String())
+	}
+	for _, expected := range []string{"12ms", "45ms", "120ms", "2s", "30000000", "15 MB/s"} {
+		if !strings.Contains(lines[1], expected) {
+			t.Errorf("%s must be in %q", expected, lines[1])
+		}
+	}
+	if !strings.Contains(lines[2], "-") {
+		t.Errorf("missing timings must be shown as -")
+	}
+}
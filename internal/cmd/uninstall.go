@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/porkbeans/hashi/internal/stateutils"
+
+	"github.com/porkbeans/hashi/pkg/errorutils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	uninstallForce bool
+)
+
+var uninstallCmd = &cobra.Command{
+	Use:   "uninstall <name>[@version]",
+	Short: "Remove files installed by hashi.",
+	Long: `Remove files installed by hashi, and their receipts.
+
+Files modified since they were installed are not removed unless --force is given.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		product, version := parseProductSpec(args[0])
+
+		state, err := stateutils.Load(stateFile)
+		if err != nil {
+			return err
+		}
+
+		receipts := state.Find(product, version)
+		if len(receipts) == 0 {
+			return fmt.Errorf("installed %s %w", args[0], errorutils.ErrNotFound)
+		}
+
+		var modified []string
+		for _, receipt := range receipts {
+			hash, err := stateutils.HashFile(receipt.Path)
+			if err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			if err == nil && hash != receipt.BinaryHash {
+				modified = append(modified, receipt.Path)
+			}
+		}
+		if len(modified) > 0 && !uninstallForce {
+			return fmt.Errorf("refused to remove files modified since install: %s", strings.Join(modified, ", "))
+		}
+
+		// Receipts of removed files are saved even if others fail, so that the state matches the disk.
+		var failures []string
+		for _, receipt := range receipts {
+			if err := os.Remove(receipt.Path); err != nil && !os.IsNotExist(err) {
+				failures = append(failures, err.Error())
+				continue
+			}
+			state.Remove(receipt.Path)
+			logf(cmd, verbosityInfo, "Removed %s %s from %s", receipt.Product, receipt.Version, receipt.Path)
+		}
+
+		if err := state.Save(stateFile); err != nil {
+			return err
+		}
+		if len(failures) > 0 {
+			return fmt.Errorf("failed to remove files: %s", strings.Join(failures, "; "))
+		}
+		return nil
+	},
+}
+
+func init() {
+	uninstallCmd.Flags().BoolVar(&uninstallForce, "force", false, "remove files even if they are modified")
+}
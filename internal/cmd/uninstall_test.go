@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/porkbeans/hashi/internal/stateutils"
+)
+
+func setupInstalled(t *testing.T) (string, func()) {
+	tempDir, err := ioutil.TempDir("", "hashi-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	originalStateFile := stateFile
+	stateFile = filepath.Join(tempDir, "state.json")
+
+	for _, name := range []string{"terraform", "vault"} {
+		if err := ioutil.WriteFile(filepath.Join(tempDir, name), []byte(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var zipChecksum [32]byte
+	if err := writeReceipts("terraform", "0.11.11", "linux", "amd64", zipChecksum, []string{filepath.Join(tempDir, "terraform")}); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeReceipts("vault", "1.0.1", "linux", "amd64", zipChecksum, []string{filepath.Join(tempDir, "vault")}); err != nil {
+		t.Fatal(err)
+	}
+
+	return tempDir, func() {
+		stateFile = originalStateFile
+		os.RemoveAll(tempDir)
+	}
+}
+
+func TestInstalledCmd(t *testing.T) {
+	tempDir, teardown := setupInstalled(t)
+	defer teardown()
+
+	buf := new(bytes.Buffer)
+	installedCmd.SetOutput(buf)
+	defer installedCmd.SetOutput(nil)
+
+	if err := installedCmd.RunE(installedCmd, []string{}); err != nil {
+		t.Fatalf("error should not happen: %s", err)
+	}
+
+	output := buf.String()
+	for _, expected := range []string{"terraform", "0.11.11", "vault", "1.0.1", "linux_amd64", filepath.Join(tempDir, "vault")} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("output must contain %s", expected)
+		}
+	}
+}
+
+func TestUninstallCmd(t *testing.T) {
+	tempDir, teardown := setupInstalled(t)
+	defer teardown()
+
+	if err := uninstallCmd.RunE(uninstallCmd, []string{"terraform@0.11.11"}); err != nil {
+		t.Fatalf("error should not happen: %s", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(tempDir, "terraform")); !os.IsNotExist(err) {
+		t.Errorf("terraform must be removed")
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "vault")); err != nil {
+		t.Errorf("vault must be kept")
+	}
+
+	state, err := stateutils.Load(stateFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(state.Receipts) != 1 || state.Receipts[0].Product != "vault" {
+		t.Errorf("only the receipt of vault must be kept")
+	}
+
+	if err := uninstallCmd.RunE(uninstallCmd, []string{"terraform"}); err == nil {
+		t.Errorf("error should happen")
+	}
+}
+
+func TestUninstallCmdModified(t *testing.T) {
+	tempDir, teardown := setupInstalled(t)
+	defer teardown()
+
+	vaultPath := filepath.Join(tempDir, "vault")
+	if err := ioutil.WriteFile(vaultPath, []byte("modified"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := uninstallCmd.RunE(uninstallCmd, []string{"vault"}); err == nil {
+		t.Errorf("error should happen")
+	}
+	if _, err := os.Stat(vaultPath); err != nil {
+		t.Errorf("vault must be kept")
+	}
+
+	defer func() { uninstallForce = false }()
+	uninstallForce = true
+	if err := uninstallCmd.RunE(uninstallCmd, []string{"vault"}); err != nil {
+		t.Fatalf("error should not happen: %s", err)
+	}
+	if _, err := os.Stat(vaultPath); !os.IsNotExist(err) {
+		t.Errorf("vault must be removed")
+	}
+}
+
+func TestUninstallCmdMissingFile(t *testing.T) {
+	tempDir, teardown := setupInstalled(t)
+	defer teardown()
+
+	if err := os.Remove(filepath.Join(tempDir, "terraform")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := uninstallCmd.RunE(uninstallCmd, []string{"terraform"}); err != nil {
+		t.Fatalf("error should not happen: %s", err)
+	}
+}
+
+func TestUninstallCmdRemoveFailure(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root can remove files in read-only directories")
+	}
+
+	tempDir, teardown := setupInstalled(t)
+	defer teardown()
+
+	readOnlyDir := filepath.Join(tempDir, "read-only")
+	if err := os.Mkdir(readOnlyDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	paths := []string{filepath.Join(tempDir, "terraform"), filepath.Join(readOnlyDir, "terraform")}
+	if err := ioutil.WriteFile(paths[1], []byte("terraform"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	var zipChecksum [32]byte
+	if err := writeReceipts("terraform", "0.11.11", "linux", "amd64", zipChecksum, paths); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(readOnlyDir, 0555); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chmod(readOnlyDir, 0755)
+
+	if err := uninstallCmd.RunE(uninstallCmd, []string{"terraform"}); err == nil || !strings.Contains(err.Error(), paths[1]) {
+		t.Errorf("error must report %s: %v", paths[1], err)
+	}
+
+	state, err := stateutils.Load(stateFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	receipts := state.Find("terraform", "")
+	if len(receipts) != 1 || receipts[0].Path != paths[1] {
+		t.Errorf("only the receipt of the file failed to be removed must be kept: %+v", receipts)
+	}
+}
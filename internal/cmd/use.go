@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var useCmd = &cobra.Command{
+	Use:   "use <name> [version]",
+	Short: "Switch the default version of a HashiCorp tool.",
+	Long: `Switch the default version of a HashiCorp tool.
+
+If version is omitted, it is read from .<name>-version, e.g. .terraform-version,
+in the current directory or its parents, or from the other sources used by shims
+(see "hashi shims install --help"). Versions can be specific ones or
+"latest", "latest:<regex>", "latest-allowed" and "min-required".
+The version is installed into the cache directory if needed.`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		product := args[0]
+
+		source := "command line"
+		var spec string
+		if len(args) > 1 {
+			spec = args[1]
+		} else {
+			var err error
+			if spec, source, err = findVersionSpec(product, "."); err != nil {
+				return err
+			}
+		}
+
+		version, err := resolveVersion(cmd, product, spec, ".")
+		if err != nil {
+			return err
+		}
+
+		binaryPath, err := ensureStored(cmd, product, version, targetGOOS, targetGOARCH)
+		if err != nil {
+			return err
+		}
+
+		if err := config.Set("versions."+product, version); err != nil {
+			return err
+		}
+		if err := config.Save(configFile); err != nil {
+			return err
+		}
+
+		logf(cmd, verbosityInfo, "Using %s %s at %s (from %s)", product, version, binaryPath, source)
+		return nil
+	},
+}
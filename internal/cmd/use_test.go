@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/porkbeans/hashi/internal/configutils"
+)
+
+func TestUseCmd(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "hashi-test-")
+	if err != nil {
+		t.Fatalf("error should not happen")
+	}
+	defer os.RemoveAll(tempDir)
+
+	defer func(originalConfig, originalCache string) {
+		configFile = originalConfig
+		cacheDir = originalCache
+	}(configFile, cacheDir)
+	configFile = filepath.Join(tempDir, "config.yaml")
+	cacheDir = tempDir
+	config = configutils.New()
+
+	if err := ioutil.WriteFile(filepath.Join(tempDir, ".consul-version"), []byte("1.4.0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	wd, _ := os.Getwd()
+	defer os.Chdir(wd)
+	if err := os.Chdir(tempDir); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := useCmd.RunE(useCmd, []string{"consul"}); err != nil {
+		t.Fatalf("error should not happen")
+	}
+
+	saved, err := configutils.Load(configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version, _ := saved.Get("versions.consul"); version != "1.4.0" {
+		t.Errorf("expected 1.4.0 but got %s", version)
+	}
+}
+
+func TestUseCmdWithoutVersion(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "hashi-test-")
+	if err != nil {
+		t.Fatalf("error should not happen")
+	}
+	defer os.RemoveAll(tempDir)
+
+	wd, _ := os.Getwd()
+	defer os.Chdir(wd)
+	if err := os.Chdir(tempDir); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := useCmd.RunE(useCmd, []string{"hashi-unknown"}); err == nil {
+		t.Errorf("error must happen")
+	}
+}
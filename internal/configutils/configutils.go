@@ -0,0 +1,183 @@
+package configutils
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// EnvPrefix is a prefix of environment variables overriding settings.
+	EnvPrefix = "HASHI_"
+)
+
+// Config represents settings loaded from a YAML file.
+type Config struct {
+	values map[interface{}]interface{}
+}
+
+// New creates an empty Config.
+func New() *Config {
+	return &Config{values: map[interface{}]interface{}{}}
+}
+
+// DefaultPath returns the default path of the config file.
+// It is $XDG_CONFIG_HOME/hashi/config.yaml, or ~/.config/hashi/config.yaml if XDG_CONFIG_HOME is not set.
+func DefaultPath() string {
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if len(configHome) == 0 {
+		configHome = filepath.Join(os.Getenv("HOME"), ".config")
+	}
+
+	return filepath.Join(configHome, "hashi", "config.yaml")
+}
+
+// EnvName returns the name of the environment variable overriding key.
+//
+//	cache-dir -> HASHI_CACHE_DIR
+func EnvName(key string) string {
+	return EnvPrefix + strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(key))
+}
+
+// Load reads a config file. It returns an empty Config if the file doesn't exist.
+func Load(filename string) (*Config, error) {
+	content, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return New(), nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	config := New()
+	if err := yaml.Unmarshal(content, &config.values); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %s", filename, err)
+	}
+	if config.values == nil {
+		config.values = map[interface{}]interface{}{}
+	}
+
+	return config, nil
+}
+
+// Save writes settings to filename readable only by the owner, creating its directory if needed.
+func (c *Config) Save(filename string) error {
+	content, err := yaml.Marshal(c.values)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filename), os.FileMode(0755)); err != nil {
+		return err
+	}
+
+	// Settings may contain credentials, so that an existing file is restricted to the owner as well.
+	if err := os.Chmod(filename, os.FileMode(0600)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return ioutil.WriteFile(filename, content, os.FileMode(0600))
+}
+
+func (c *Config) lookup(names []string) (interface{}, bool) {
+	var value interface{} = c.values
+	for _, name := range names {
+		m, ok := value.(map[interface{}]interface{})
+		if !ok {
+			return nil, false
+		}
+
+		if value, ok = m[name]; !ok {
+			return nil, false
+		}
+	}
+
+	return value, true
+}
+
+// Get returns a scalar value of key. Nested keys are separated by dots.
+func (c *Config) Get(key string) (string, bool) {
+	return c.GetPath(strings.Split(key, ".")...)
+}
+
+// GetPath returns a scalar value of nested keys, which may contain dots such as host names.
+func (c *Config) GetPath(names ...string) (string, bool) {
+	value, ok := c.lookup(names)
+	if !ok || value == nil {
+		return "", false
+	}
+
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		return "", false
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			values = append(values, fmt.Sprint(item))
+		}
+		return strings.Join(values, ","), true
+	default:
+		return fmt.Sprint(v), true
+	}
+}
+
+// Set sets a scalar value to key. Nested keys are separated by dots.
+func (c *Config) Set(key string, value string) error {
+	names := strings.Split(key, ".")
+	m := c.values
+	for _, name := range names[:len(names)-1] {
+		if len(name) == 0 {
+			return fmt.Errorf("invalid key %s", key)
+		}
+
+		child, ok := m[name].(map[interface{}]interface{})
+		if !ok {
+			if _, exists := m[name]; exists {
+				return fmt.Errorf("%s is not a section", key)
+			}
+			child = map[interface{}]interface{}{}
+			m[name] = child
+		}
+		m = child
+	}
+
+	name := names[len(names)-1]
+	if len(name) == 0 {
+		return fmt.Errorf("invalid key %s", key)
+	}
+	if _, ok := m[name].(map[interface{}]interface{}); ok {
+		return errors.New(key + " is a section")
+	}
+
+	m[name] = value
+	return nil
+}
+
+func flatten(prefix string, m map[interface{}]interface{}, keys []string) []string {
+	for name, value := range m {
+		key := fmt.Sprint(name)
+		if len(prefix) > 0 {
+			key = prefix + "." + key
+		}
+
+		if child, ok := value.(map[interface{}]interface{}); ok {
+			keys = flatten(key, child, keys)
+		} else {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys
+}
+
+// Keys returns sorted keys of all scalar values.
+func (c *Config) Keys() []string {
+	keys := flatten("", c.values, nil)
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,194 @@
+package configutils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+
+	"github.com/porkbeans/hashi/internal/ioutils"
+)
+
+func TestDefaultPath(t *testing.T) {
+	defer os.Setenv("XDG_CONFIG_HOME", os.Getenv("XDG_CONFIG_HOME"))
+
+	os.Setenv("XDG_CONFIG_HOME", "/tmp/xdg")
+	if actual := DefaultPath(); actual != "/tmp/xdg/hashi/config.yaml" {
+		t.Errorf("unexpected path %s", actual)
+	}
+
+	os.Setenv("XDG_CONFIG_HOME", "")
+	if expected := filepath.Join(os.Getenv("HOME"), ".config", "hashi", "config.yaml"); DefaultPath() != expected {
+		t.Errorf("expected %s but got %s", expected, DefaultPath())
+	}
+}
+
+func TestEnvName(t *testing.T) {
+	testCases := map[string]string{
+		"os":          "HASHI_OS",
+		"cache-dir":   "HASHI_CACHE_DIR",
+		"mirror.base": "HASHI_MIRROR_BASE",
+	}
+
+	for key, expected := range testCases {
+		if actual := EnvName(key); actual != expected {
+			t.Errorf("expected %s but got %s", expected, actual)
+		}
+	}
+}
+
+func TestLoadNotExist(t *testing.T) {
+	config, err := Load(filepath.Join(os.TempDir(), "hashi-nonexistence", "config.yaml"))
+	if err != nil {
+		t.Fatalf("error should not happen")
+	}
+
+	if len(config.Keys()) != 0 {
+		t.Errorf("config must be empty")
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	file, err := ioutil.TempFile("", "hashi-test-")
+	if err != nil {
+		t.Fatalf("error should not happen")
+	}
+	defer ioutils.Remove(file.Name())
+
+	_, _ = file.WriteString("os: [linux")
+	_ = file.Close()
+
+	if _, err := Load(file.Name()); err == nil {
+		t.Errorf("error must happen")
+	}
+}
+
+func TestSetGetSave(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "hashi-test-")
+	if err != nil {
+		t.Fatalf("error should not happen")
+	}
+	defer os.RemoveAll(tempDir)
+
+	config := New()
+	if err := config.Set("os", "darwin"); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.Set("versions.terraform", "0.11.14"); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.Set("versions", "invalid"); err == nil {
+		t.Errorf("error must happen")
+	}
+	if err := config.Set("os.name", "invalid"); err == nil {
+		t.Errorf("error must happen")
+	}
+
+	filename := filepath.Join(tempDir, "hashi", "config.yaml")
+	if err := config.Save(filename); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := Load(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if value, ok := loaded.Get("os"); !ok || value != "darwin" {
+		t.Errorf("expected darwin but got %s", value)
+	}
+	if value, ok := loaded.Get("versions.terraform"); !ok || value != "0.11.14" {
+		t.Errorf("expected 0.11.14 but got %s", value)
+	}
+	if _, ok := loaded.Get("versions"); ok {
+		t.Errorf("section must not be a value")
+	}
+	if _, ok := loaded.Get("arch"); ok {
+		t.Errorf("arch must not be set")
+	}
+
+	expectedKeys := []string{"os", "versions.terraform"}
+	if !reflect.DeepEqual(loaded.Keys(), expectedKeys) {
+		t.Errorf("expected %v but got %v", expectedKeys, loaded.Keys())
+	}
+}
+
+func TestGetList(t *testing.T) {
+	file, err := ioutil.TempFile("", "hashi-test-")
+	if err != nil {
+		t.Fatalf("error should not happen")
+	}
+	defer ioutils.Remove(file.Name())
+
+	_, _ = file.WriteString("exclude:\n  - LICENSE*\n  - README*\n")
+	_ = file.Close()
+
+	config, err := Load(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if value, _ := config.Get("exclude"); value != "LICENSE*,README*" {
+		t.Errorf("unexpected value %s", value)
+	}
+}
+
+func TestGetPath(t *testing.T) {
+	file, err := ioutil.TempFile("", "hashi-test-")
+	if err != nil {
+		t.Fatalf("error should not happen")
+	}
+	defer ioutils.Remove(file.Name())
+
+	_, _ = file.WriteString("credentials:\n  mirror.example.com:\n    token: abc\n")
+	_ = file.Close()
+
+	config, err := Load(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if value, ok := config.GetPath("credentials", "mirror.example.com", "token"); !ok || value != "abc" {
+		t.Errorf("expected abc but got %s", value)
+	}
+	if _, ok := config.Get("credentials.mirror.example.com.token"); ok {
+		t.Errorf("dotted host must not be found by Get")
+	}
+	if _, ok := config.GetPath("credentials", "mirror.example.com"); ok {
+		t.Errorf("section must not be a value")
+	}
+}
+
+func TestSaveMode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes are not supported")
+	}
+
+	tempDir, err := ioutil.TempDir("", "hashi-test-")
+	if err != nil {
+		t.Fatalf("error should not happen")
+	}
+	defer os.RemoveAll(tempDir)
+
+	created := filepath.Join(tempDir, "created.yaml")
+	existing := filepath.Join(tempDir, "existing.yaml")
+	if err := ioutil.WriteFile(existing, []byte("os: linux\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, filename := range []string{created, existing} {
+		if err := New().Save(filename); err != nil {
+			t.Fatal(err)
+		}
+
+		info, err := os.Stat(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if mode := info.Mode().Perm(); mode != 0600 {
+			t.Errorf("%s: expected 0600 but got %o", filename, mode)
+		}
+	}
+}
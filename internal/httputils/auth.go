@@ -0,0 +1,77 @@
+package httputils
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Credential authenticates requests to a host.
+// Token is sent as a bearer token if set, otherwise Username and Password are sent with basic auth.
+type Credential struct {
+	Username string
+	Password string
+	Token    string
+}
+
+func (c Credential) empty() bool {
+	return len(c.Token) == 0 && len(c.Username) == 0 && len(c.Password) == 0
+}
+
+// AuthTransport adds the Credential of each request's host to requests sent with Base.
+// Lookup is called with host:port first if the URL has a port, then with the host name.
+// Requests with their own Authorization header or user info in URL are sent as they are.
+type AuthTransport struct {
+	Base   http.RoundTripper
+	Lookup func(host string) (Credential, bool)
+}
+
+// RoundTrip sends req with the Credential of its host.
+func (t *AuthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if len(req.Header.Get("Authorization")) > 0 || req.URL.User != nil {
+		return t.Base.RoundTrip(req)
+	}
+
+	credential, ok := t.lookup(req.URL)
+	if !ok {
+		return t.Base.RoundTrip(req)
+	}
+
+	req = req.Clone(req.Context())
+	if len(credential.Token) > 0 {
+		req.Header.Set("Authorization", "Bearer "+credential.Token)
+	} else {
+		req.SetBasicAuth(credential.Username, credential.Password)
+	}
+
+	return t.Base.RoundTrip(req)
+}
+
+func (t *AuthTransport) lookup(u *url.URL) (Credential, bool) {
+	hosts := []string{strings.ToLower(u.Hostname())}
+	if len(u.Port()) > 0 {
+		hosts = append([]string{strings.ToLower(u.Host)}, hosts...)
+	}
+
+	for _, host := range hosts {
+		if credential, ok := t.Lookup(host); ok && !credential.empty() {
+			return credential, true
+		}
+	}
+
+	return Credential{}, false
+}
+
+// RedactURL replaces the password in rawURL with "xxxxx" so that it can be logged.
+// Query parameters are kept as they are.
+func RedactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.User == nil {
+		return rawURL
+	}
+
+	if _, ok := u.User.Password(); ok {
+		u.User = url.UserPassword(u.User.Username(), "xxxxx")
+	}
+	return u.String()
+}
@@ -0,0 +1,274 @@
+package httputils
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/porkbeans/hashi/internal/ioutils"
+	"github.com/porkbeans/hashi/pkg/errorutils"
+)
+
+// DefaultMaxCacheSize is the default limit of the size of a cached response.
+const DefaultMaxCacheSize = 16 << 20
+
+// CacheEntry represents validators of a cached response.
+type CacheEntry struct {
+	URL          string    `json:"url"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	ContentType  string    `json:"content_type,omitempty"`
+	Fetched      time.Time `json:"fetched"`
+}
+
+// CacheTransport caches successful responses of GET requests in Dir with their ETag and Last-Modified.
+// A cached response is used without a request for TTL, then revalidated with a conditional request
+// and used again if the server responds 304 Not Modified.
+// Responses larger than MaxSize (DefaultMaxCacheSize if 0) and requests rejected by Cacheable are not cached.
+// If Refresh is true, cached responses are neither used nor revalidated, but replaced.
+//
+// If Offline is true, cached responses are used regardless of TTL, and other requests fail with
+// errorutils.NotCachedError without being sent. A cached response is also used if a request fails
+// before a response unless Refresh is true. Such responses may be stale, and are reported with Warnf.
+type CacheTransport struct {
+	Base      http.RoundTripper
+	Dir       string
+	TTL       time.Duration
+	Refresh   bool
+	Offline   bool
+	MaxSize   int64
+	Cacheable func(request *http.Request) bool
+	Logf      func(format string, args ...interface{})
+	Warnf     func(format string, args ...interface{})
+}
+
+func (t *CacheTransport) base() http.RoundTripper {
+	if t.Base == nil {
+		return http.DefaultTransport
+	}
+	return t.Base
+}
+
+func (t *CacheTransport) logf(format string, args ...interface{}) {
+	if t.Logf != nil {
+		t.Logf(format, args...)
+	}
+}
+
+func (t *CacheTransport) warnf(format string, args ...interface{}) {
+	if t.Warnf != nil {
+		t.Warnf(format, args...)
+	}
+}
+
+func (t *CacheTransport) maxSize() int64 {
+	if t.MaxSize <= 0 {
+		return DefaultMaxCacheSize
+	}
+	return t.MaxSize
+}
+
+// cachePath returns a path of the cached file of url without an extension.
+func (t *CacheTransport) cachePath(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(t.Dir, hex.EncodeToString(sum[:]))
+}
+
+// Lookup returns the cache entry of url.
+func (t *CacheTransport) Lookup(url string) (CacheEntry, bool) {
+	entry := CacheEntry{}
+
+	content, err := ioutil.ReadFile(t.cachePath(url) + ".json")
+	if err != nil {
+		return entry, false
+	}
+	if err := json.Unmarshal(content, &entry); err != nil {
+		return entry, false
+	}
+	if _, err := os.Stat(t.cachePath(url) + ".body"); err != nil {
+		return entry, false
+	}
+
+	return entry, true
+}
+
+// writeCacheFile writes content to path atomically.
+func writeCacheFile(path string, content []byte) error {
+	tempPath := path + ".hashi-new"
+	if err := ioutil.WriteFile(tempPath, content, os.FileMode(0600)); err != nil {
+		ioutils.Remove(tempPath)
+		return err
+	}
+
+	return os.Rename(tempPath, path)
+}
+
+func (t *CacheTransport) saveEntry(url string, entry CacheEntry) error {
+	content, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	return writeCacheFile(t.cachePath(url)+".json", content)
+}
+
+func (t *CacheTransport) save(url string, entry CacheEntry, body []byte) error {
+	if err := os.MkdirAll(t.Dir, os.FileMode(0700)); err != nil {
+		return err
+	}
+
+	if err := writeCacheFile(t.cachePath(url)+".body", body); err != nil {
+		return err
+	}
+
+	return t.saveEntry(url, entry)
+}
+
+// cachedResponse returns a response of request with the cached body of url.
+func (t *CacheTransport) cachedResponse(request *http.Request, entry CacheEntry) (*http.Response, error) {
+	file, err := os.Open(t.cachePath(request.URL.String()) + ".body")
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		ioutils.Close(file)
+		return nil, err
+	}
+
+	header := http.Header{}
+	header.Set("Content-Length", strconv.FormatInt(info.Size(), 10))
+	for name, value := range map[string]string{"Content-Type": entry.ContentType, "ETag": entry.ETag, "Last-Modified": entry.LastModified} {
+		if len(value) > 0 {
+			header.Set(name, value)
+		}
+	}
+
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          file,
+		ContentLength: info.Size(),
+		Request:       request,
+	}, nil
+}
+
+// multiReadCloser reads Reader and closes Closer.
+type multiReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// store caches resp unless its body is larger than MaxSize, and returns a response with the same body.
+func (t *CacheTransport) store(request *http.Request, resp *http.Response) (*http.Response, error) {
+	url := request.URL.String()
+	if resp.ContentLength > t.maxSize() {
+		return resp, nil
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, t.maxSize()+1))
+	if err != nil {
+		ioutils.Close(resp.Body)
+		return nil, err
+	}
+	if int64(len(body)) > t.maxSize() {
+		resp.Body = multiReadCloser{Reader: io.MultiReader(bytes.NewReader(body), resp.Body), Closer: resp.Body}
+		return resp, nil
+	}
+	ioutils.Close(resp.Body)
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	entry := CacheEntry{
+		URL:          RedactURL(url),
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		ContentType:  resp.Header.Get("Content-Type"),
+		Fetched:      time.Now().UTC(),
+	}
+	if err := t.save(url, entry, body); err != nil {
+		t.logf("Failed to cache %s: %s", RedactURL(url), err)
+	}
+
+	return resp, nil
+}
+
+// RoundTrip returns a cached response if it is fresh or not modified, otherwise sends request and caches the response.
+func (t *CacheTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	url := request.URL.String()
+	if request.Method != http.MethodGet || len(request.Header.Get("Range")) > 0 || (t.Cacheable != nil && !t.Cacheable(request)) {
+		if t.Offline {
+			return nil, &errorutils.NotCachedError{Artifact: RedactURL(url)}
+		}
+		return t.base().RoundTrip(request)
+	}
+
+	entry, cached := t.Lookup(url)
+	if t.Offline {
+		if !cached {
+			return nil, &errorutils.NotCachedError{Artifact: RedactURL(url)}
+		}
+
+		t.warnf("Working offline with %s cached at %s, which may be stale", RedactURL(url), entry.Fetched.Local().Format(time.RFC3339))
+		return t.cachedResponse(request, entry)
+	}
+
+	if cached && !t.Refresh {
+		if age := time.Since(entry.Fetched); age < t.TTL {
+			t.logf("Using %s cached %s ago", RedactURL(url), age.Round(time.Second))
+			return t.cachedResponse(request, entry)
+		}
+
+		if len(entry.ETag) > 0 || len(entry.LastModified) > 0 {
+			request = request.Clone(request.Context())
+			if len(entry.ETag) > 0 {
+				request.Header.Set("If-None-Match", entry.ETag)
+			}
+			if len(entry.LastModified) > 0 {
+				request.Header.Set("If-Modified-Since", entry.LastModified)
+			}
+		}
+	}
+
+	resp, err := t.base().RoundTrip(request)
+	if err != nil {
+		if !cached || t.Refresh {
+			return nil, err
+		}
+
+		t.warnf("Failed to fetch %s: %s; using the copy cached at %s, which may be stale", RedactURL(url), err, entry.Fetched.Local().Format(time.RFC3339))
+		return t.cachedResponse(request, entry)
+	}
+
+	if cached && !t.Refresh && resp.StatusCode == http.StatusNotModified {
+		ioutils.Close(resp.Body)
+		if etag := resp.Header.Get("ETag"); len(etag) > 0 {
+			entry.ETag = etag
+		}
+		entry.Fetched = time.Now().UTC()
+		if err := t.saveEntry(url, entry); err != nil {
+			t.logf("Failed to cache %s: %s", RedactURL(url), err)
+		}
+
+		t.logf("Revalidated %s cached in %s", RedactURL(url), t.Dir)
+		return t.cachedResponse(request, entry)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return resp, nil
+	}
+
+	return t.store(request, resp)
+}
@@ -0,0 +1,201 @@
+package httputils
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/porkbeans/hashi/internal/ioutils"
+	"github.com/porkbeans/hashi/pkg/errorutils"
+)
+
+type cacheTestServer struct {
+	body        string
+	etag        string
+	requests    int
+	conditional int
+}
+
+func (s *cacheTestServer) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	s.requests++
+	if request.URL.Path == "/missing" {
+		writer.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	writer.Header().Set("ETag", s.etag)
+	writer.Header().Set("Content-Type", "text/html")
+	if match := request.Header.Get("If-None-Match"); len(match) > 0 {
+		s.conditional++
+		if match == s.etag {
+			writer.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+
+	_, _ = writer.Write([]byte(s.body))
+}
+
+func getBody(t *testing.T, client HTTPGetClient, url string) string {
+	t.Helper()
+
+	resp, err := Get(client, url)
+	if err != nil {
+		t.Fatalf("error should not happen: %s", err)
+	}
+	defer ioutils.Close(resp.Body)
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("error should not happen: %s", err)
+	}
+
+	return string(body)
+}
+
+func TestCacheTransport(t *testing.T) {
+	upstream := &cacheTestServer{body: "v1", etag: `"v1"`}
+	server := httptest.NewServer(upstream)
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	transport := &CacheTransport{Dir: dir, TTL: time.Hour}
+	client := &http.Client{Transport: transport}
+
+	testCases := []struct {
+		name        string
+		prepare     func()
+		expected    string
+		requests    int
+		conditional int
+	}{
+		{"first fetch", func() {}, "v1", 1, 0},
+		{"fresh", func() {}, "v1", 1, 0},
+		{"not modified", func() { transport.TTL = 0 }, "v1", 2, 1},
+		{"modified", func() { upstream.body, upstream.etag = "v2", `"v2"` }, "v2", 3, 2},
+		{"refresh", func() { transport.Refresh = true }, "v2", 4, 2},
+	}
+
+	for _, testCase := range testCases {
+		testCase.prepare()
+		if actual := getBody(t, client, server.URL+"/terraform/"); actual != testCase.expected {
+			t.Errorf("%s: expected %s but got %s", testCase.name, testCase.expected, actual)
+		}
+		if upstream.requests != testCase.requests || upstream.conditional != testCase.conditional {
+			t.Errorf("%s: expected %d requests and %d conditional requests but got %d and %d",
+				testCase.name, testCase.requests, testCase.conditional, upstream.requests, upstream.conditional)
+		}
+	}
+
+	entry, ok := transport.Lookup(server.URL + "/terraform/")
+	if !ok {
+		t.Fatal("cache entry should exist")
+	}
+	if entry.ETag != `"v2"` || entry.ContentType != "text/html" || entry.URL != server.URL+"/terraform/" {
+		t.Errorf("unexpected cache entry %+v", entry)
+	}
+}
+
+func TestCacheTransportNotCached(t *testing.T) {
+	upstream := &cacheTestServer{body: strings.Repeat("x", 64), etag: `"x"`}
+	server := httptest.NewServer(upstream)
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	transport := &CacheTransport{
+		Dir:     dir,
+		TTL:     time.Hour,
+		MaxSize: 32,
+		Cacheable: func(request *http.Request) bool {
+			return !strings.HasSuffix(request.URL.Path, ".zip")
+		},
+	}
+	client := &http.Client{Transport: transport}
+
+	for _, path := range []string{"/large", "/terraform.zip"} {
+		for i := 0; i < 2; i++ {
+			if actual := getBody(t, client, server.URL+path); actual != upstream.body {
+				t.Errorf("expected %s but got %s", upstream.body, actual)
+			}
+		}
+		if _, ok := transport.Lookup(server.URL + path); ok {
+			t.Errorf("%s should not be cached", path)
+		}
+	}
+
+	if _, err := Get(client, server.URL+"/missing"); err == nil {
+		t.Error("error should happen")
+	}
+	if _, ok := transport.Lookup(server.URL + "/missing"); ok {
+		t.Error("error responses should not be cached")
+	}
+
+	if upstream.requests != 5 {
+		t.Errorf("expected 5 requests but got %d", upstream.requests)
+	}
+}
+
+func TestCacheTransportOffline(t *testing.T) {
+	upstream := &cacheTestServer{body: "v1", etag: `"v1"`}
+	server := httptest.NewServer(upstream)
+
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	var warnings []string
+	transport := &CacheTransport{
+		Dir: dir,
+		Warnf: func(format string, args ...interface{}) {
+			warnings = append(warnings, format)
+		},
+	}
+	client := &http.Client{Transport: transport}
+
+	if actual := getBody(t, client, server.URL+"/terraform/"); actual != "v1" {
+		t.Errorf("expected v1 but got %s", actual)
+	}
+	server.Close()
+
+	// The cached response is used when the server is unreachable, and offline.
+	for _, offline := range []bool{false, true} {
+		transport.Offline = offline
+		warnings = nil
+		if actual := getBody(t, client, server.URL+"/terraform/"); actual != "v1" {
+			t.Errorf("expected v1 but got %s", actual)
+		}
+		if len(warnings) != 1 || !strings.Contains(warnings[0], "may be stale") {
+			t.Errorf("stale response must be warned: %v", warnings)
+		}
+	}
+
+	_, err = Get(client, server.URL+"/vault/")
+	if !errors.Is(err, errorutils.ErrNotCached) {
+		t.Errorf("not cached error must happen: %v", err)
+	}
+	if expected := server.URL + "/vault/ has never been cached and cannot be fetched offline"; err == nil || err.Error() != expected {
+		t.Errorf("expected %s but got %v", expected, err)
+	}
+
+	transport.Offline, transport.Refresh = false, true
+	if _, err := Get(client, server.URL+"/terraform/"); !errors.Is(err, errorutils.ErrNetwork) {
+		t.Errorf("network error must happen with Refresh: %v", err)
+	}
+}
@@ -1,8 +1,12 @@
 package httputils
 
 import (
-	"fmt"
+	"errors"
 	"net/http"
+	"time"
+
+	"github.com/porkbeans/hashi/internal/ioutils"
+	"github.com/porkbeans/hashi/pkg/errorutils"
 )
 
 // HTTPGetClient represents HTTP client have Get method.
@@ -10,24 +14,52 @@ type HTTPGetClient interface {
 	Get(url string) (resp *http.Response, err error)
 }
 
+// DefaultClient is used by Get if client is nil.
+var DefaultClient HTTPGetClient = http.DefaultClient
+
+// LoggingClient logs a summary of each request and response of Client with Logf.
+type LoggingClient struct {
+	Client HTTPGetClient
+	Logf   func(format string, args ...interface{})
+}
+
+// Get retrieves url with Client and logs the summary. Passwords in url are redacted.
+func (c *LoggingClient) Get(url string) (*http.Response, error) {
+	start := time.Now()
+	redacted := RedactURL(url)
+	c.Logf("GET %s", redacted)
+
+	resp, err := c.Client.Get(url)
+	elapsed := time.Since(start).Round(time.Millisecond)
+	if err != nil {
+		c.Logf("GET %s failed in %s: %s", redacted, elapsed, err)
+		return nil, err
+	}
+
+	c.Logf("GET %s: %s, %d bytes of %s in %s", redacted, resp.Status, resp.ContentLength, resp.Header.Get("Content-Type"), elapsed)
+	return resp, nil
+}
+
 // Get retrieves resources from specified URL. returns error if status code is not 200.
+// Errors are errorutils.NetworkError or errorutils.StatusError, whose URL is redacted,
+// or errorutils.NotCachedError returned by CacheTransport offline.
 func Get(client HTTPGetClient, url string) (*http.Response, error) {
 	if client == nil {
-		client = http.DefaultClient
+		client = DefaultClient
 	}
 
 	resp, err := client.Get(url)
 	if err != nil {
-		return nil, err
+		var notCachedErr *errorutils.NotCachedError
+		if errors.As(err, &notCachedErr) {
+			return nil, notCachedErr
+		}
+		return nil, &errorutils.NetworkError{URL: RedactURL(url), Err: err}
 	}
 
-	switch resp.StatusCode {
-	case 200:
-		break
-	case 403:
-		return nil, fmt.Errorf("%s not found", url)
-	default:
-		return nil, fmt.Errorf("failed to get %s (status: %d)", url, resp.StatusCode)
+	if resp.StatusCode != 200 {
+		ioutils.Close(resp.Body)
+		return nil, &errorutils.StatusError{URL: RedactURL(url), StatusCode: resp.StatusCode}
 	}
 
 	return resp, nil
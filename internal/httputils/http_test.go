@@ -1,10 +1,14 @@
 package httputils
 
 import (
+	"errors"
+	"fmt"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/porkbeans/hashi/internal/testutils"
+	"github.com/porkbeans/hashi/pkg/errorutils"
 	"github.com/porkbeans/hashi/pkg/urlutils"
 )
 
@@ -17,8 +21,8 @@ func TestGetOK(t *testing.T) {
 
 func TestGetNil(t *testing.T) {
 	_, err := Get(nil, "")
-	if err == nil {
-		t.Errorf("error must happen")
+	if !errors.Is(err, errorutils.ErrNetwork) {
+		t.Errorf("network error must happen")
 	}
 }
 
@@ -29,6 +33,32 @@ func TestGetNotFound(t *testing.T) {
 	}
 }
 
+func TestGetStatus(t *testing.T) {
+	testCases := []struct {
+		statusCode int
+		expected   error
+	}{
+		{403, errorutils.ErrNotFound},
+		{404, errorutils.ErrNotFound},
+		{502, errorutils.ErrNetwork},
+	}
+
+	for _, testCase := range testCases {
+		server := httptest.NewServer(
+			testutils.TestServerHandler{
+				StatusCode: testCase.statusCode,
+				Content:    "Failed",
+			},
+		)
+
+		_, err := Get(server.Client(), server.URL)
+		if !errors.Is(err, testCase.expected) {
+			t.Errorf("%d: expected %v but got %v", testCase.statusCode, testCase.expected, err)
+		}
+		server.Close()
+	}
+}
+
 func TestGetOther(t *testing.T) {
 	server := httptest.NewServer(
 		testutils.TestServerHandler{
@@ -44,3 +74,35 @@ func TestGetOther(t *testing.T) {
 		t.Errorf("error must happen")
 	}
 }
+
+func TestLoggingClient(t *testing.T) {
+	server := httptest.NewServer(
+		testutils.TestServerHandler{
+			StatusCode: 200,
+			Content:    "OK",
+		},
+	)
+	defer server.Close()
+
+	var logs []string
+	client := &LoggingClient{
+		Client: server.Client(),
+		Logf: func(format string, args ...interface{}) {
+			logs = append(logs, fmt.Sprintf(format, args...))
+		},
+	}
+
+	resp, err := Get(client, server.URL)
+	if err != nil {
+		t.Fatalf("error should not happen")
+	}
+	resp.Body.Close()
+
+	if len(logs) != 2 || !strings.Contains(logs[1], "200 OK") {
+		t.Errorf("unexpected logs %v", logs)
+	}
+
+	if _, err := Get(client, ""); err == nil || len(logs) != 4 || !strings.Contains(logs[3], "failed") {
+		t.Errorf("failure must be logged: %v", logs)
+	}
+}
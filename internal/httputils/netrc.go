@@ -0,0 +1,108 @@
+package httputils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// netrcDefault is the key of the default entry of .netrc.
+const netrcDefault = ""
+
+// Netrc represents credentials in a .netrc file by machine name.
+type Netrc map[string]Credential
+
+// NetrcPath returns $NETRC, or ~/.netrc (~/_netrc on Windows).
+func NetrcPath() string {
+	if path := os.Getenv("NETRC"); len(path) > 0 {
+		return path
+	}
+
+	name := ".netrc"
+	if runtime.GOOS == "windows" {
+		name = "_netrc"
+	}
+	return filepath.Join(os.Getenv("HOME"), name)
+}
+
+// ParseNetrc parses the content of a .netrc file. Macros are skipped.
+func ParseNetrc(content string) Netrc {
+	netrc := Netrc{}
+
+	var machine *string
+	var credential Credential
+	flush := func() {
+		if machine != nil {
+			if _, exists := netrc[*machine]; !exists {
+				netrc[*machine] = credential
+			}
+		}
+		machine = nil
+		credential = Credential{}
+	}
+
+	lines := strings.Split(content, "\n")
+	for i := 0; i < len(lines); i++ {
+		fields := strings.Fields(lines[i])
+		for j := 0; j < len(fields); j++ {
+			next := func() string {
+				if j+1 < len(fields) {
+					j++
+					return fields[j]
+				}
+				return ""
+			}
+
+			switch fields[j] {
+			case "machine":
+				flush()
+				name := strings.ToLower(next())
+				machine = &name
+			case "default":
+				flush()
+				name := netrcDefault
+				machine = &name
+			case "login":
+				credential.Username = next()
+			case "password":
+				credential.Password = next()
+			case "account":
+				next()
+			case "macdef":
+				// A macro lasts until an empty line.
+				flush()
+				for i+1 < len(lines) && len(strings.TrimSpace(lines[i+1])) > 0 {
+					i++
+				}
+				j = len(fields)
+			}
+		}
+	}
+	flush()
+
+	return netrc
+}
+
+// LoadNetrc reads a .netrc file. It returns an empty Netrc if the file doesn't exist.
+func LoadNetrc(filename string) (Netrc, error) {
+	content, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return Netrc{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return ParseNetrc(string(content)), nil
+}
+
+// Lookup returns the Credential of host, or the default one.
+func (n Netrc) Lookup(host string) (Credential, bool) {
+	if credential, ok := n[strings.ToLower(host)]; ok {
+		return credential, true
+	}
+
+	credential, ok := n[netrcDefault]
+	return credential, ok
+}
@@ -0,0 +1,77 @@
+package httputils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseNetrc(t *testing.T) {
+	netrc := ParseNetrc(`
+machine artifactory.example.com
+  login alice
+  password s3cret
+machine Mirror.Example.com login bob password hunter2 account ignored
+
+macdef init
+machine macro.example.com login nobody
+
+machine token.example.com password abc
+default login anonymous password guest
+`)
+
+	testCases := map[string]Credential{
+		"artifactory.example.com": {Username: "alice", Password: "s3cret"},
+		"mirror.example.com":      {Username: "bob", Password: "hunter2"},
+		"token.example.com":       {Password: "abc"},
+		"unknown.example.com":     {Username: "anonymous", Password: "guest"},
+	}
+
+	for host, expected := range testCases {
+		if actual, ok := netrc.Lookup(host); !ok || actual != expected {
+			t.Errorf("%s: expected %+v but got %+v", host, expected, actual)
+		}
+	}
+
+	if _, ok := netrc["macro.example.com"]; ok {
+		t.Errorf("macro must be skipped")
+	}
+}
+
+func TestLoadNetrc(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "hashi-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	filename := filepath.Join(tempDir, ".netrc")
+	netrc, err := LoadNetrc(filename)
+	if err != nil || len(netrc) != 0 {
+		t.Errorf("missing file must be empty")
+	}
+
+	if err := ioutil.WriteFile(filename, []byte("machine example.com login user password pass\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	netrc, err = LoadNetrc(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := netrc.Lookup("other.example.com"); ok {
+		t.Errorf("credential must not be found without default")
+	}
+	if credential, ok := netrc.Lookup("example.com"); !ok || credential.Username != "user" {
+		t.Errorf("unexpected credential %+v", credential)
+	}
+}
+
+func TestNetrcPath(t *testing.T) {
+	defer os.Setenv("NETRC", os.Getenv("NETRC"))
+
+	os.Setenv("NETRC", "/tmp/netrc")
+	if actual := NetrcPath(); actual != "/tmp/netrc" {
+		t.Errorf("unexpected path %s", actual)
+	}
+}
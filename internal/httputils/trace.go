@@ -0,0 +1,149 @@
+package httputils
+
+import (
+	"crypto/tls"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// Trace records timings of a request.
+// TTFB is measured from the start of the request, and Transfer from the first byte of the response to the end of the body.
+type Trace struct {
+	URL      string
+	DNS      time.Duration
+	Connect  time.Duration
+	TLS      time.Duration
+	TTFB     time.Duration
+	Transfer time.Duration
+	Bytes    int64
+
+	mutex        sync.Mutex
+	start        time.Time
+	dnsStart     time.Time
+	connectStart time.Time
+	tlsStart     time.Time
+	firstByte    time.Time
+	done         bool
+}
+
+// BytesPerSecond returns the transfer rate of the response body.
+func (t *Trace) BytesPerSecond() float64 {
+	if t.Transfer <= 0 {
+		return 0
+	}
+	return float64(t.Bytes) / t.Transfer.Seconds()
+}
+
+func (t *Trace) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			t.record(func() { t.dnsStart = time.Now() })
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			t.record(func() { t.DNS = time.Since(t.dnsStart) })
+		},
+		ConnectStart: func(string, string) {
+			t.record(func() { t.connectStart = time.Now() })
+		},
+		ConnectDone: func(string, string, error) {
+			t.record(func() { t.Connect = time.Since(t.connectStart) })
+		},
+		TLSHandshakeStart: func() {
+			t.record(func() { t.tlsStart = time.Now() })
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			t.record(func() { t.TLS = time.Since(t.tlsStart) })
+		},
+		GotFirstResponseByte: func() {
+			t.record(func() {
+				t.firstByte = time.Now()
+				t.TTFB = t.firstByte.Sub(t.start)
+			})
+		},
+	}
+}
+
+func (t *Trace) record(f func()) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	f()
+}
+
+func (t *Trace) read(n int) {
+	t.record(func() { t.Bytes += int64(n) })
+}
+
+func (t *Trace) finish() {
+	t.record(func() {
+		if t.done {
+			return
+		}
+		t.done = true
+		if !t.firstByte.IsZero() {
+			t.Transfer = time.Since(t.firstByte)
+		}
+	})
+}
+
+// tracedBody counts bytes of a response body and finishes its Trace at EOF or Close.
+type tracedBody struct {
+	io.ReadCloser
+	trace *Trace
+}
+
+func (b *tracedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.trace.read(n)
+	if err == io.EOF {
+		b.trace.finish()
+	}
+	return n, err
+}
+
+func (b *tracedBody) Close() error {
+	b.trace.finish()
+	return b.ReadCloser.Close()
+}
+
+// TracingClient records a Trace of each request made with Client.
+type TracingClient struct {
+	Client *http.Client
+
+	mutex  sync.Mutex
+	traces []*Trace
+}
+
+// Get retrieves url with Client and records its Trace.
+func (c *TracingClient) Get(url string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	trace := &Trace{URL: RedactURL(url), start: time.Now()}
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace.clientTrace()))
+
+	c.mutex.Lock()
+	c.traces = append(c.traces, trace)
+	c.mutex.Unlock()
+
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		trace.finish()
+		return nil, err
+	}
+
+	resp.Body = &tracedBody{ReadCloser: resp.Body, trace: trace}
+	return resp, nil
+}
+
+// Traces returns recorded traces in the order of requests.
+func (c *TracingClient) Traces() []*Trace {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return append([]*Trace(nil), c.traces...)
+}
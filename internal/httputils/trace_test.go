@@ -0,0 +1,69 @@
+package httputils
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/porkbeans/hashi/internal/ioutils"
+	"github.com/porkbeans/hashi/internal/testutils"
+)
+
+func TestTracingClient(t *testing.T) {
+	server := httptest.NewTLSServer(
+		testutils.TestServerHandler{
+			StatusCode: 200,
+			Content:    "Hello, world",
+		},
+	)
+	defer server.Close()
+
+	client := &TracingClient{Client: server.Client()}
+	for i := 0; i < 2; i++ {
+		resp, err := Get(client, server.URL)
+		if err != nil {
+			t.Fatalf("error should not happen")
+		}
+		if _, err := ioutil.ReadAll(resp.Body); err != nil {
+			t.Fatal(err)
+		}
+		ioutils.Close(resp.Body)
+	}
+
+	traces := client.Traces()
+	if len(traces) != 2 {
+		t.Fatalf("expected 2 traces but got %d", len(traces))
+	}
+
+	first := traces[0]
+	if first.URL != server.URL || first.Bytes != int64(len("Hello, world")) {
+		t.Errorf("unexpected trace %+v", first)
+	}
+	if first.TLS <= 0 || first.Connect <= 0 || first.TTFB <= 0 {
+		t.Errorf("timings must be recorded: %+v", first)
+	}
+	if traces[1].TLS != 0 {
+		t.Errorf("connection must be reused")
+	}
+}
+
+func TestTracingClientFail(t *testing.T) {
+	client := &TracingClient{Client: &http.Client{}}
+	if _, err := Get(client, "http://127.0.0.1:1/"); err == nil {
+		t.Errorf("error must happen")
+	}
+
+	if len(client.Traces()) != 1 {
+		t.Errorf("failed request must be traced")
+	}
+}
+
+func TestTraceBytesPerSecond(t *testing.T) {
+	if (&Trace{Bytes: 100}).BytesPerSecond() != 0 {
+		t.Errorf("rate must be 0 without transfer time")
+	}
+	if (&Trace{Bytes: 100, Transfer: 2e9}).BytesPerSecond() != 50 {
+		t.Errorf("rate must be 50")
+	}
+}
@@ -0,0 +1,70 @@
+package httputils
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+)
+
+// TransportOptions configures a Transport made by NewTransport.
+// CACert is a PEM file of certificates trusted in addition to the system ones.
+// Proxy is used for all requests if set, otherwise proxies are taken from HTTPS_PROXY, HTTP_PROXY and NO_PROXY.
+type TransportOptions struct {
+	CACert             string
+	ClientCert         string
+	ClientKey          string
+	InsecureSkipVerify bool
+	Proxy              string
+}
+
+// NewTransport returns a copy of http.DefaultTransport configured with options.
+func NewTransport(options TransportOptions) (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	tlsConfig := &tls.Config{InsecureSkipVerify: options.InsecureSkipVerify}
+
+	if len(options.CACert) > 0 {
+		pem, err := ioutil.ReadFile(options.CACert)
+		if err != nil {
+			return nil, err
+		}
+
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in %s", options.CACert)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if len(options.ClientCert) > 0 || len(options.ClientKey) > 0 {
+		if len(options.ClientCert) == 0 || len(options.ClientKey) == 0 {
+			return nil, errors.New("both of client certificate and key are required")
+		}
+
+		certificate, err := tls.LoadX509KeyPair(options.ClientCert, options.ClientKey)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+	transport.TLSClientConfig = tlsConfig
+
+	if len(options.Proxy) > 0 {
+		proxyURL, err := url.Parse(options.Proxy)
+		if err != nil {
+			return nil, err
+		}
+		if len(proxyURL.Scheme) == 0 || len(proxyURL.Host) == 0 {
+			return nil, fmt.Errorf("invalid proxy URL %s", options.Proxy)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	return transport, nil
+}
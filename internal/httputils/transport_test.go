@@ -0,0 +1,180 @@
+package httputils
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/porkbeans/hashi/internal/ioutils"
+	"github.com/porkbeans/hashi/internal/testutils"
+)
+
+func writePEM(t *testing.T, filename, blockType string, content []byte) {
+	if err := ioutil.WriteFile(filename, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: content}), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// createClientCert writes a self-signed client certificate and its key, and returns the certificate.
+func createClientCert(t *testing.T, certFile, keyFile string) *x509.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "hashi"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writePEM(t, certFile, "CERTIFICATE", der)
+	writePEM(t, keyFile, "EC PRIVATE KEY", keyDER)
+
+	certificate, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return certificate
+}
+
+func get(transport *http.Transport, url string) error {
+	resp, err := Get(&http.Client{Transport: transport}, url)
+	if err != nil {
+		return err
+	}
+	ioutils.Close(resp.Body)
+	return nil
+}
+
+func TestNewTransportCACert(t *testing.T) {
+	server := httptest.NewTLSServer(testutils.TestServerHandler{StatusCode: 200, Content: "OK"})
+	defer server.Close()
+
+	tempDir, err := ioutil.TempDir("", "hashi-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	transport, err := NewTransport(TransportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := get(transport, server.URL); err == nil {
+		t.Errorf("unknown CA must be rejected")
+	}
+
+	caFile := filepath.Join(tempDir, "ca.pem")
+	writePEM(t, caFile, "CERTIFICATE", server.Certificate().Raw)
+	transport, err = NewTransport(TransportOptions{CACert: caFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := get(transport, server.URL); err != nil {
+		t.Errorf("error should not happen: %s", err)
+	}
+
+	transport, err = NewTransport(TransportOptions{InsecureSkipVerify: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := get(transport, server.URL); err != nil {
+		t.Errorf("error should not happen: %s", err)
+	}
+
+	if _, err := NewTransport(TransportOptions{CACert: filepath.Join(tempDir, "ca.pem.missing")}); err == nil {
+		t.Errorf("error must happen")
+	}
+	if err := ioutil.WriteFile(caFile, []byte("invalid"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewTransport(TransportOptions{CACert: caFile}); err == nil {
+		t.Errorf("error must happen")
+	}
+}
+
+func TestNewTransportClientCert(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "hashi-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	certFile := filepath.Join(tempDir, "client.pem")
+	keyFile := filepath.Join(tempDir, "client-key.pem")
+	clientCert := createClientCert(t, certFile, keyFile)
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert)
+	server := httptest.NewUnstartedServer(testutils.TestServerHandler{StatusCode: 200, Content: "OK"})
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	server.StartTLS()
+	defer server.Close()
+
+	transport, err := NewTransport(TransportOptions{InsecureSkipVerify: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := get(transport, server.URL); err == nil {
+		t.Errorf("request without client certificate must be rejected")
+	}
+
+	transport, err = NewTransport(TransportOptions{ClientCert: certFile, ClientKey: keyFile, InsecureSkipVerify: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := get(transport, server.URL); err != nil {
+		t.Errorf("error should not happen: %s", err)
+	}
+
+	if _, err := NewTransport(TransportOptions{ClientCert: certFile}); err == nil {
+		t.Errorf("key must be required")
+	}
+}
+
+func TestNewTransportProxy(t *testing.T) {
+	proxied := false
+	proxy := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		proxied = request.URL.Host == "releases.example.com"
+		writer.WriteHeader(200)
+	}))
+	defer proxy.Close()
+
+	transport, err := NewTransport(TransportOptions{Proxy: proxy.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := get(transport, "http://releases.example.com/"); err != nil || !proxied {
+		t.Errorf("request must be sent to the proxy: %v", err)
+	}
+
+	if _, err := NewTransport(TransportOptions{Proxy: "proxy.example.com"}); err == nil {
+		t.Errorf("error must happen")
+	}
+}
@@ -3,6 +3,7 @@ package ioutils
 import (
 	"io"
 	"os"
+	"path/filepath"
 )
 
 // Remove removes specified file with suppressing error.
@@ -18,3 +19,28 @@ func Close(c io.Closer) {
 		_ = c.Close()
 	}
 }
+
+// CopyFile copies src to dst, creating parent directories of dst if needed.
+func CopyFile(dst string, src string, perm os.FileMode) error {
+	srcFile, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer Close(srcFile)
+
+	if err := os.MkdirAll(filepath.Dir(dst), os.FileMode(0755)); err != nil {
+		return err
+	}
+
+	dstFile, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(dstFile, srcFile); err != nil {
+		Close(dstFile)
+		return err
+	}
+
+	return dstFile.Close()
+}
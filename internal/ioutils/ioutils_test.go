@@ -3,6 +3,7 @@ package ioutils
 import (
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -22,3 +23,30 @@ func TestRemoveClose(t *testing.T) {
 		t.Errorf("file should be removed")
 	}
 }
+
+func TestCopyFile(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	src := filepath.Join(tempDir, "src")
+	if err := ioutil.WriteFile(src, []byte("content"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := filepath.Join(tempDir, "a", "b", "dst")
+	if err := CopyFile(dst, src, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := ioutil.ReadFile(dst)
+	if err != nil || string(content) != "content" {
+		t.Errorf("file must be copied")
+	}
+
+	if err := CopyFile(dst, filepath.Join(tempDir, "nonexistence"), 0600); err == nil {
+		t.Errorf("error must happen")
+	}
+}
@@ -0,0 +1,135 @@
+package stateutils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/porkbeans/hashi/internal/ioutils"
+)
+
+// Receipt records a file installed by hashi.
+// Adopted is set for a file installed by other means and registered later.
+type Receipt struct {
+	Product     string    `json:"product"`
+	Version     string    `json:"version"`
+	Os          string    `json:"os"`
+	Arch        string    `json:"arch"`
+	Path        string    `json:"path"`
+	ZipChecksum string    `json:"zip_checksum"`
+	BinaryHash  string    `json:"binary_hash"`
+	InstalledAt time.Time `json:"installed_at"`
+	Adopted     bool      `json:"adopted,omitempty"`
+}
+
+// State represents receipts of installs.
+type State struct {
+	Receipts []Receipt `json:"receipts"`
+}
+
+// DefaultPath returns the default path of the state file.
+// It is $XDG_STATE_HOME/hashi/state.json, or ~/.local/state/hashi/state.json if XDG_STATE_HOME is not set.
+func DefaultPath() string {
+	stateHome := os.Getenv("XDG_STATE_HOME")
+	if len(stateHome) == 0 {
+		stateHome = filepath.Join(os.Getenv("HOME"), ".local", "state")
+	}
+
+	return filepath.Join(stateHome, "hashi", "state.json")
+}
+
+// Load reads a state file. It returns an empty State if the file doesn't exist.
+func Load(filename string) (*State, error) {
+	content, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return &State{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	state := &State{}
+	if err := json.Unmarshal(content, state); err != nil {
+		return nil, err
+	}
+
+	return state, nil
+}
+
+// Save writes the state to filename atomically.
+func (s *State) Save(filename string) error {
+	content, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(filename)
+	if err := os.MkdirAll(dir, os.FileMode(0755)); err != nil {
+		return err
+	}
+
+	tempFile, err := ioutil.TempFile(dir, ".state-")
+	if err != nil {
+		return err
+	}
+	defer ioutils.Remove(tempFile.Name())
+
+	if _, err := tempFile.Write(content); err != nil {
+		ioutils.Close(tempFile)
+		return err
+	}
+	if err := tempFile.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tempFile.Name(), filename)
+}
+
+// Add adds a receipt, replacing the one of the same path.
+func (s *State) Add(receipt Receipt) {
+	s.Remove(receipt.Path)
+	s.Receipts = append(s.Receipts, receipt)
+}
+
+// Remove removes the receipt of path.
+func (s *State) Remove(path string) {
+	receipts := s.Receipts[:0]
+	for _, receipt := range s.Receipts {
+		if receipt.Path != path {
+			receipts = append(receipts, receipt)
+		}
+	}
+	s.Receipts = receipts
+}
+
+// Find returns receipts of product. Any version matches if version is empty.
+func (s *State) Find(product, version string) []Receipt {
+	var receipts []Receipt
+	for _, receipt := range s.Receipts {
+		if receipt.Product == product && (len(version) == 0 || receipt.Version == version) {
+			receipts = append(receipts, receipt)
+		}
+	}
+
+	return receipts
+}
+
+// HashFile returns the SHA256 hash of a file in hex.
+func HashFile(filename string) (string, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return "", err
+	}
+	defer ioutils.Close(file)
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,101 @@
+package stateutils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDefaultPath(t *testing.T) {
+	defer os.Setenv("XDG_STATE_HOME", os.Getenv("XDG_STATE_HOME"))
+
+	os.Setenv("XDG_STATE_HOME", "/tmp/xdg")
+	if actual := DefaultPath(); actual != "/tmp/xdg/hashi/state.json" {
+		t.Errorf("unexpected path %s", actual)
+	}
+}
+
+func TestLoadSave(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "hashi-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	filename := filepath.Join(tempDir, "hashi", "state.json")
+	state, err := Load(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(state.Receipts) != 0 {
+		t.Errorf("state must be empty")
+	}
+
+	installedAt := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	state.Add(Receipt{Product: "terraform", Version: "0.11.10", Path: "/usr/local/bin/terraform", InstalledAt: installedAt})
+	state.Add(Receipt{Product: "terraform", Version: "0.11.11", Path: "/usr/local/bin/terraform", InstalledAt: installedAt})
+	state.Add(Receipt{Product: "vault", Version: "1.0.1", Path: "/usr/local/bin/vault", InstalledAt: installedAt})
+	if err := state.Save(filename); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := Load(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(loaded.Receipts) != 2 {
+		t.Fatalf("expected 2 receipts but got %d", len(loaded.Receipts))
+	}
+	if receipts := loaded.Find("terraform", ""); len(receipts) != 1 || receipts[0].Version != "0.11.11" || !receipts[0].InstalledAt.Equal(installedAt) {
+		t.Errorf("unexpected receipts %+v", receipts)
+	}
+	if receipts := loaded.Find("terraform", "0.11.10"); len(receipts) != 0 {
+		t.Errorf("unexpected receipts %+v", receipts)
+	}
+
+	loaded.Remove("/usr/local/bin/vault")
+	if receipts := loaded.Find("vault", ""); len(receipts) != 0 {
+		t.Errorf("receipt must be removed")
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	file, err := ioutil.TempFile("", "hashi-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+
+	_, _ = file.WriteString("{")
+	_ = file.Close()
+
+	if _, err := Load(file.Name()); err == nil {
+		t.Errorf("error must happen")
+	}
+}
+
+func TestHashFile(t *testing.T) {
+	file, err := ioutil.TempFile("", "hashi-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+
+	_, _ = file.WriteString("hello")
+	_ = file.Close()
+
+	hash, err := HashFile(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hash != "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824" {
+		t.Errorf("unexpected hash %s", hash)
+	}
+
+	if _, err := HashFile(file.Name() + ".nonexistence"); err == nil {
+		t.Errorf("error must happen")
+	}
+}
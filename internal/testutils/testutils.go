@@ -113,6 +113,11 @@ func TouchTempFile() (string, error) {
 
 // CreateTempZip creates a zip file that contains a file
 func CreateTempZip(filenameInZip, content string) (string, error) {
+	return CreateTempZipFiles(map[string]string{filenameInZip: content})
+}
+
+// CreateTempZipFiles creates a zip file that contains files mapped from names to contents
+func CreateTempZipFiles(files map[string]string) (string, error) {
 	tempFile, err := ioutil.TempFile("", "hashi-test-")
 	zipFile := NewErrorWriter(tempFile, err)
 	defer ioutils.Close(tempFile)
@@ -120,11 +125,13 @@ func CreateTempZip(filenameInZip, content string) (string, error) {
 	zipWriter := zip.NewWriter(zipFile)
 	defer ioutils.Close(zipWriter)
 
-	fileWriter := NewErrorWriter(zipWriter.Create(filenameInZip))
-	_, _ = fileWriter.Write([]byte(content))
+	for filenameInZip, content := range files {
+		fileWriter := NewErrorWriter(zipWriter.Create(filenameInZip))
+		_, _ = fileWriter.Write([]byte(content))
 
-	if fileWriter.Err() != nil {
-		return "", fileWriter.Err()
+		if fileWriter.Err() != nil {
+			return "", fileWriter.Err()
+		}
 	}
 
 	return tempFile.Name(), nil
@@ -162,3 +162,21 @@ func TestCreateTempZip2(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestCreateTempZipFiles(t *testing.T) {
+	filename, err := CreateTempZipFiles(map[string]string{"a": "A", "dir/b": "B"})
+	if err != nil {
+		t.Fatalf("error should not happen")
+	}
+	defer ioutils.Remove(filename)
+
+	zipReader, err := zip.OpenReader(filename)
+	if err != nil {
+		t.Fatalf("error should not happen")
+	}
+	defer ioutils.Close(zipReader)
+
+	if len(zipReader.File) != 2 {
+		t.Fatalf("zip must contain 2 files")
+	}
+}
@@ -0,0 +1,118 @@
+package errorutils
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Errors returned by hashi. Use errors.Is to check them.
+var (
+	// ErrNotFound means that a product, a version, a file or a URL does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrChecksumMismatch means that a downloaded file doesn't match its published checksum.
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+	// ErrSignatureInvalid means that a checksum file isn't signed by a trusted key.
+	ErrSignatureInvalid = errors.New("invalid signature")
+	// ErrNetwork means that a request failed before a successful response.
+	ErrNetwork = errors.New("network error")
+	// ErrPlatformUnavailable means that a release isn't published for the requested os and arch.
+	ErrPlatformUnavailable = errors.New("platform unavailable")
+	// ErrNotCached means that an artifact needed offline has never been cached.
+	ErrNotCached = errors.New("not cached")
+)
+
+// Exit codes of hashi for each error.
+const (
+	ExitOK                  = 0
+	ExitError               = 1
+	ExitNotFound            = 3
+	ExitChecksumMismatch    = 4
+	ExitSignatureInvalid    = 5
+	ExitNetwork             = 6
+	ExitPlatformUnavailable = 7
+	ExitNotCached           = 8
+)
+
+// ExitCode returns the exit code for err.
+// Security errors take precedence over others.
+func ExitCode(err error) int {
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.Is(err, ErrSignatureInvalid):
+		return ExitSignatureInvalid
+	case errors.Is(err, ErrChecksumMismatch):
+		return ExitChecksumMismatch
+	case errors.Is(err, ErrPlatformUnavailable):
+		return ExitPlatformUnavailable
+	case errors.Is(err, ErrNotCached):
+		return ExitNotCached
+	case errors.Is(err, ErrNotFound):
+		return ExitNotFound
+	case errors.Is(err, ErrNetwork):
+		return ExitNetwork
+	default:
+		return ExitError
+	}
+}
+
+// StatusError represents an unexpected HTTP status.
+// 403 and 404 are ErrNotFound because the releases site responds 403 for missing files,
+// and 5xx are ErrNetwork.
+type StatusError struct {
+	URL        string
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	if e.Is(ErrNotFound) {
+		return fmt.Sprintf("%s not found (status: %d)", e.URL, e.StatusCode)
+	}
+	return fmt.Sprintf("failed to get %s (status: %d)", e.URL, e.StatusCode)
+}
+
+// Is reports whether the status means target.
+func (e *StatusError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == 403 || e.StatusCode == 404
+	case ErrNetwork:
+		return e.StatusCode >= 500
+	default:
+		return false
+	}
+}
+
+// NetworkError represents a failure to connect or to read a response.
+type NetworkError struct {
+	URL string
+	Err error
+}
+
+func (e *NetworkError) Error() string {
+	return fmt.Sprintf("failed to get %s: %s", e.URL, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *NetworkError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is ErrNetwork.
+func (e *NetworkError) Is(target error) bool {
+	return target == ErrNetwork
+}
+
+// NotCachedError represents an artifact which cannot be fetched offline because it has never been cached.
+type NotCachedError struct {
+	Artifact string
+}
+
+func (e *NotCachedError) Error() string {
+	return fmt.Sprintf("%s has never been cached and cannot be fetched offline", e.Artifact)
+}
+
+// Is reports whether target is ErrNotCached.
+func (e *NotCachedError) Is(target error) bool {
+	return target == ErrNotCached
+}
@@ -0,0 +1,67 @@
+package errorutils
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+)
+
+func TestExitCode(t *testing.T) {
+	testCases := []struct {
+		err      error
+		expected int
+	}{
+		{nil, ExitOK},
+		{errors.New("error"), ExitError},
+		{fmt.Errorf("terraform 0.99.0: %w", ErrNotFound), ExitNotFound},
+		{fmt.Errorf("terraform_0.11.14_linux_amd64.zip: %w", ErrChecksumMismatch), ExitChecksumMismatch},
+		{fmt.Errorf("%w: SHA256SUMS.sig", ErrSignatureInvalid), ExitSignatureInvalid},
+		{fmt.Errorf("hashi: %w", ErrPlatformUnavailable), ExitPlatformUnavailable},
+		{&NetworkError{URL: "https://example.com", Err: io.ErrUnexpectedEOF}, ExitNetwork},
+		{&NetworkError{URL: "https://example.com", Err: &NotCachedError{Artifact: "https://example.com"}}, ExitNotCached},
+		{&StatusError{URL: "https://example.com", StatusCode: 404}, ExitNotFound},
+		{&StatusError{URL: "https://example.com", StatusCode: 403}, ExitNotFound},
+		{&StatusError{URL: "https://example.com", StatusCode: 503}, ExitNetwork},
+		{&StatusError{URL: "https://example.com", StatusCode: 401}, ExitError},
+	}
+
+	for _, testCase := range testCases {
+		if actual := ExitCode(testCase.err); actual != testCase.expected {
+			t.Errorf("%v: expected %d but got %d", testCase.err, testCase.expected, actual)
+		}
+	}
+}
+
+func TestNetworkError(t *testing.T) {
+	err := fmt.Errorf("wrapped: %w", &NetworkError{URL: "https://example.com", Err: io.ErrUnexpectedEOF})
+
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("underlying error must be unwrapped")
+	}
+
+	var networkErr *NetworkError
+	if !errors.As(err, &networkErr) || networkErr.URL != "https://example.com" {
+		t.Errorf("NetworkError must be extracted")
+	}
+}
+
+func TestStatusError(t *testing.T) {
+	if actual := (&StatusError{URL: "https://example.com", StatusCode: 404}).Error(); actual != "https://example.com not found (status: 404)" {
+		t.Errorf("unexpected message %s", actual)
+	}
+	if actual := (&StatusError{URL: "https://example.com", StatusCode: 500}).Error(); actual != "failed to get https://example.com (status: 500)" {
+		t.Errorf("unexpected message %s", actual)
+	}
+}
+
+func TestNotCachedError(t *testing.T) {
+	err := fmt.Errorf("wrapped: %w", &NotCachedError{Artifact: "https://example.com/vault/"})
+
+	if !errors.Is(err, ErrNotCached) {
+		t.Errorf("ErrNotCached must be matched")
+	}
+	if expected := "wrapped: https://example.com/vault/ has never been cached and cannot be fetched offline"; err.Error() != expected {
+		t.Errorf("expected %s but got %s", expected, err)
+	}
+}
@@ -48,6 +48,38 @@ func ParseLinkList(baseURL *url.URL, node *html.Node) LinkList {
 	return linkList
 }
 
+// ParseAnchorList parses html to a list of all links, for directory listings not using list items.
+func ParseAnchorList(baseURL *url.URL, node *html.Node) LinkList {
+	linkList := LinkList{}
+
+	for c := node.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && c.Data == "a" {
+			text := ""
+			if c.FirstChild != nil {
+				text = c.FirstChild.Data
+			}
+
+			for _, attr := range c.Attr {
+				if attr.Key == "href" {
+					relativeURL, err := url.Parse(attr.Val)
+					if err != nil {
+						continue
+					}
+
+					linkList = append(linkList, LinkEntry{
+						Name: text,
+						URL:  baseURL.ResolveReference(relativeURL).String(),
+					})
+				}
+			}
+		} else if c.FirstChild != nil {
+			linkList = append(linkList, ParseAnchorList(baseURL, c)...)
+		}
+	}
+
+	return linkList
+}
+
 // ProductVersionEntry represents a link of specific version of a HashiCorp product.
 type ProductVersionEntry struct {
 	Name    string
@@ -139,6 +171,26 @@ func (l LinkList) ProductZipList() ProductZipList {
 	return zipList
 }
 
+// MatchProductZipList returns links to zips of product whose URL matches pattern.
+// The pattern captures "version", "os" and "arch", e.g. a pattern made by urlutils.Mirror.ZipPattern.
+func (l LinkList) MatchProductZipList(product string, pattern *regexp.Regexp) ProductZipList {
+	zipList := ProductZipList{}
+
+	for _, link := range l {
+		if matches, matched := mapSubmatchNames(pattern, link.URL); matched {
+			zipList = append(zipList, ProductZipEntry{
+				Name:    product,
+				Version: matches["version"],
+				Os:      matches["os"],
+				Arch:    matches["arch"],
+				URL:     link.URL,
+			})
+		}
+	}
+
+	return zipList
+}
+
 // ChecksumEntry represents a checksum for a HashiCorp product's zip file.
 type ChecksumEntry struct {
 	Name     string
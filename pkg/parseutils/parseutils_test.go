@@ -199,3 +199,58 @@ func TestParseChecksumList(t *testing.T) {
 		t.Logf("%s %s %s %s -> %s\n", actualList[i].Name, actualList[i].Version, actualList[i].Os, actualList[i].Arch, hex.EncodeToString(actualList[i].Checksum[:]))
 	}
 }
+
+func TestParseAnchorList(t *testing.T) {
+	node, err := html.Parse(bytes.NewBufferString(`<html><body><pre><a href="../">../</a>
+<a href="terraform-0.11.14-linux-amd64.zip">terraform-0.11.14-linux-amd64.zip</a> 2019-05-16
+<a href="https://other.example.com/vault-1.0.1-linux-amd64.zip"></a></pre></body></html>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	baseURL, _ := url.Parse("https://mirror.example.com/hashicorp/")
+	actualList := ParseAnchorList(baseURL, node)
+	expectedList := LinkList{
+		{Name: "../", URL: "https://mirror.example.com/"},
+		{Name: "terraform-0.11.14-linux-amd64.zip", URL: "https://mirror.example.com/hashicorp/terraform-0.11.14-linux-amd64.zip"},
+		{Name: "", URL: "https://other.example.com/vault-1.0.1-linux-amd64.zip"},
+	}
+
+	if len(expectedList) != len(actualList) {
+		t.Fatalf("failed to parse list %+v", actualList)
+	}
+	for i := range expectedList {
+		if expectedList[i] != actualList[i] {
+			t.Errorf("%+v is not equal to %+v", expectedList[i], actualList[i])
+		}
+	}
+}
+
+func TestLinkList_MatchProductZipList(t *testing.T) {
+	mirror := urlutils.Mirror{Zip: "{{.Base}}/hashicorp/{{.Product}}-{{.Version}}-{{.OS}}-{{.Arch}}.zip"}
+	pattern, err := mirror.ZipPattern("terraform")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	linkList := LinkList{
+		{Name: "../", URL: "https://mirror.example.com/"},
+		{Name: "terraform-0.11.14-linux-amd64.zip", URL: "https://mirror.example.com/hashicorp/terraform-0.11.14-linux-amd64.zip"},
+		{Name: "terraform-0.12.0-beta1-darwin-amd64.zip", URL: "https://mirror.example.com/hashicorp/terraform-0.12.0-beta1-darwin-amd64.zip"},
+		{Name: "vault-1.0.1-linux-amd64.zip", URL: "https://mirror.example.com/hashicorp/vault-1.0.1-linux-amd64.zip"},
+	}
+	actualList := linkList.MatchProductZipList("terraform", pattern)
+	expectedList := ProductZipList{
+		{Name: "terraform", Version: "0.11.14", Os: "linux", Arch: "amd64", URL: linkList[1].URL},
+		{Name: "terraform", Version: "0.12.0-beta1", Os: "darwin", Arch: "amd64", URL: linkList[2].URL},
+	}
+
+	if len(expectedList) != len(actualList) {
+		t.Fatalf("failed to parse list %+v", actualList)
+	}
+	for i := range expectedList {
+		if expectedList[i] != actualList[i] {
+			t.Errorf("%+v is not equal to %+v", expectedList[i], actualList[i])
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package progressutils
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// Types of events.
+const (
+	EventResolve           = "resolve"
+	EventDownloadStart     = "download-start"
+	EventProgress          = "progress"
+	EventChecksumVerified  = "checksum-verified"
+	EventSignatureVerified = "signature-verified"
+	EventExtracted         = "extracted"
+	EventChecked           = "checked"
+	EventInstalled         = "installed"
+	EventError             = "error"
+	EventLog               = "log"
+)
+
+// unknownSizeInterval is the number of bytes between progress events when the total size is unknown.
+const unknownSizeInterval = 1 << 20
+
+// Event represents a step of resolving, downloading and installing a product.
+// Message is a human readable description of the event.
+type Event struct {
+	Type     string    `json:"event"`
+	Time     time.Time `json:"time"`
+	Product  string    `json:"product,omitempty"`
+	Version  string    `json:"version,omitempty"`
+	URL      string    `json:"url,omitempty"`
+	Path     string    `json:"path,omitempty"`
+	Bytes    int64     `json:"bytes,omitempty"`
+	Total    int64     `json:"total,omitempty"`
+	Percent  int       `json:"percent,omitempty"`
+	ExitCode int       `json:"exit_code,omitempty"`
+	Message  string    `json:"message,omitempty"`
+}
+
+// Observer receives events.
+type Observer interface {
+	Observe(event Event)
+}
+
+// ObserverFunc adapts a function to Observer.
+type ObserverFunc func(event Event)
+
+// Observe calls f with event.
+func (f ObserverFunc) Observe(event Event) {
+	f(event)
+}
+
+// Discard is an Observer ignoring all events.
+var Discard Observer = ObserverFunc(func(Event) {})
+
+// JSONObserver writes events as newline-delimited JSON.
+type JSONObserver struct {
+	mutex   sync.Mutex
+	encoder *json.Encoder
+}
+
+// NewJSONObserver returns a JSONObserver writing to writer.
+func NewJSONObserver(writer io.Writer) *JSONObserver {
+	return &JSONObserver{encoder: json.NewEncoder(writer)}
+}
+
+// Observe writes event as a line of JSON. Time is set to the current time if it is zero.
+func (o *JSONObserver) Observe(event Event) {
+	if event.Time.IsZero() {
+		event.Time = time.Now().UTC()
+	}
+
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	_ = o.encoder.Encode(event)
+}
+
+// Reader reports EventProgress to Observer every Step percent while reading.
+// Fields of Event are copied to each progress event, and Total is the expected size.
+// If Total is not positive, progress is reported every megabyte instead.
+// The last event is reported at EOF with Bytes equal to Total.
+type Reader struct {
+	Reader   io.Reader
+	Observer Observer
+	Event    Event
+	Step     int
+
+	read     int64
+	reported int64
+	done     bool
+}
+
+// Read reads from the underlying reader and reports progress.
+func (r *Reader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	r.read += int64(n)
+
+	if err == io.EOF {
+		r.finish()
+	} else if r.Event.Total > 0 {
+		step := r.Event.Total * int64(r.step()) / 100
+		if step <= 0 || r.read-r.reported >= step {
+			r.report(r.read, r.Event.Total)
+		}
+	} else if r.read-r.reported >= unknownSizeInterval {
+		r.report(r.read, r.Event.Total)
+	}
+
+	return n, err
+}
+
+func (r *Reader) step() int {
+	if r.Step <= 0 || r.Step > 100 {
+		return 100
+	}
+	return r.Step
+}
+
+func (r *Reader) finish() {
+	if r.done {
+		return
+	}
+	r.done = true
+
+	// The last step has been reported already if Total was exact.
+	if r.reported == r.read && r.read == r.Event.Total && r.read > 0 {
+		return
+	}
+	r.report(r.read, r.read)
+}
+
+func (r *Reader) report(read, total int64) {
+	r.reported = read
+
+	event := r.Event
+	event.Type = EventProgress
+	event.Bytes = read
+	event.Total = total
+	if total > 0 {
+		event.Percent = int(read * 100 / total)
+	}
+	r.Observer.Observe(event)
+}
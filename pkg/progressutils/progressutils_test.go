@@ -0,0 +1,122 @@
+package progressutils
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+)
+
+type recorder struct {
+	events []Event
+}
+
+func (r *recorder) Observe(event Event) {
+	r.events = append(r.events, event)
+}
+
+// smallReader returns at most 10 bytes at a time to report progress many times.
+type smallReader struct {
+	reader io.Reader
+}
+
+func (r smallReader) Read(p []byte) (int, error) {
+	if len(p) > 10 {
+		p = p[:10]
+	}
+	return r.reader.Read(p)
+}
+
+func TestReader(t *testing.T) {
+	observer := &recorder{}
+	reader := &Reader{
+		Reader:   smallReader{strings.NewReader(strings.Repeat("a", 100))},
+		Observer: observer,
+		Event:    Event{URL: "https://example.com", Total: 100},
+		Step:     25,
+	}
+
+	if _, err := io.Copy(ioutil.Discard, reader); err != nil {
+		t.Fatal(err)
+	}
+
+	var percents []int
+	for _, event := range observer.events {
+		if event.Type != EventProgress || event.URL != "https://example.com" || event.Total != 100 {
+			t.Errorf("unexpected event %+v", event)
+		}
+		percents = append(percents, event.Percent)
+	}
+
+	expected := []int{30, 60, 90, 100}
+	if len(percents) != len(expected) {
+		t.Fatalf("expected %v but got %v", expected, percents)
+	}
+	for i := range expected {
+		if percents[i] != expected[i] {
+			t.Errorf("expected %v but got %v", expected, percents)
+		}
+	}
+}
+
+func TestReaderUnknownSize(t *testing.T) {
+	observer := &recorder{}
+	reader := &Reader{
+		Reader:   strings.NewReader(strings.Repeat("a", unknownSizeInterval+1)),
+		Observer: observer,
+		Event:    Event{Total: -1},
+		Step:     10,
+	}
+
+	if _, err := io.Copy(ioutil.Discard, reader); err != nil {
+		t.Fatal(err)
+	}
+
+	last := observer.events[len(observer.events)-1]
+	if last.Bytes != unknownSizeInterval+1 || last.Total != last.Bytes || last.Percent != 100 {
+		t.Errorf("unexpected last event %+v", last)
+	}
+}
+
+func TestJSONObserver(t *testing.T) {
+	buf := new(bytes.Buffer)
+	observer := NewJSONObserver(buf)
+
+	fixed := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	observer.Observe(Event{Type: EventDownloadStart, Time: fixed, URL: "https://example.com"})
+	observer.Observe(Event{Type: EventInstalled, Path: "/usr/local/bin/vault"})
+
+	scanner := bufio.NewScanner(buf)
+	var events []Event
+	for scanner.Scan() {
+		var event Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatalf("each line must be JSON: %s", err)
+		}
+		events = append(events, event)
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events but got %d", len(events))
+	}
+	if !events[0].Time.Equal(fixed) || events[0].URL != "https://example.com" {
+		t.Errorf("unexpected event %+v", events[0])
+	}
+	if events[1].Time.IsZero() || events[1].Type != EventInstalled {
+		t.Errorf("unexpected event %+v", events[1])
+	}
+}
+
+func TestObserverFunc(t *testing.T) {
+	called := false
+	ObserverFunc(func(Event) { called = true }).Observe(Event{})
+	Discard.Observe(Event{})
+
+	if !called {
+		t.Errorf("function must be called")
+	}
+}
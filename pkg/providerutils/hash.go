@@ -0,0 +1,62 @@
+package providerutils
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Hash1 returns a "h1:" hash of files, which is the dirhash used in .terraform.lock.hcl.
+// open is called for each file name to read its content.
+func Hash1(files []string, open func(string) (io.ReadCloser, error)) (string, error) {
+	h := sha256.New()
+	files = append([]string(nil), files...)
+	sort.Strings(files)
+
+	for _, file := range files {
+		if strings.Contains(file, "\n") {
+			return "", errors.New("file names with newlines are not supported")
+		}
+
+		reader, err := open(file)
+		if err != nil {
+			return "", err
+		}
+
+		fileHash := sha256.New()
+		_, err = io.Copy(fileHash, reader)
+		_ = reader.Close()
+		if err != nil {
+			return "", err
+		}
+
+		_, _ = fmt.Fprintf(h, "%x  %s\n", fileHash.Sum(nil), file)
+	}
+
+	return "h1:" + base64.StdEncoding.EncodeToString(h.Sum(nil)), nil
+}
+
+// Hash1Zip returns a "h1:" hash of contents of a provider zip.
+func Hash1Zip(filename string) (string, error) {
+	zipReader, err := zip.OpenReader(filename)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = zipReader.Close() }()
+
+	files := make([]string, 0, len(zipReader.File))
+	zipFiles := map[string]*zip.File{}
+	for _, file := range zipReader.File {
+		files = append(files, file.Name)
+		zipFiles[file.Name] = file
+	}
+
+	return Hash1(files, func(name string) (io.ReadCloser, error) {
+		return zipFiles[name].Open()
+	})
+}
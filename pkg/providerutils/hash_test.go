@@ -0,0 +1,47 @@
+package providerutils
+
+import (
+	"testing"
+
+	"github.com/porkbeans/hashi/internal/ioutils"
+	"github.com/porkbeans/hashi/internal/testutils"
+)
+
+func TestHash1Zip(t *testing.T) {
+	filename, err := testutils.CreateTempZipFiles(map[string]string{
+		"terraform-provider-null_v3.2.1_x5": "binary",
+		"LICENSE.txt":                       "license",
+	})
+	if err != nil {
+		t.Fatalf("error should not happen")
+	}
+	defer ioutils.Remove(filename)
+
+	expected := "h1:DqRqJBoECx0c/4UuYRmi5X4CB27AS6+4K8zk0nl2bvs="
+	actual, err := Hash1Zip(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if actual != expected {
+		t.Errorf("expected %s but got %s", expected, actual)
+	}
+}
+
+func TestHash1ZipInvalid(t *testing.T) {
+	filename, err := testutils.TouchTempFile()
+	if err != nil {
+		t.Fatalf("error should not happen")
+	}
+	defer ioutils.Remove(filename)
+
+	if _, err := Hash1Zip(filename); err == nil {
+		t.Errorf("error must happen")
+	}
+}
+
+func TestZipHash(t *testing.T) {
+	if actual := ZipHash([]byte{0x01, 0xab}); actual != "zh:01ab" {
+		t.Errorf("unexpected hash %s", actual)
+	}
+}
@@ -0,0 +1,132 @@
+package providerutils
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/hcl2/gohcl"
+	"github.com/hashicorp/hcl2/hclparse"
+)
+
+const lockFileHeader = `# This file is maintained automatically by "terraform init".
+# Manual edits may be lost in future updates.
+`
+
+// LockEntry represents a provider block in .terraform.lock.hcl.
+type LockEntry struct {
+	Provider    string
+	Version     string
+	Constraints string
+	Hashes      []string
+}
+
+// LockFile represents .terraform.lock.hcl.
+type LockFile struct {
+	Entries []LockEntry
+}
+
+// lockFileBody is the schema of .terraform.lock.hcl.
+type lockFileBody struct {
+	Providers []struct {
+		Provider    string   `hcl:"provider,label"`
+		Version     string   `hcl:"version"`
+		Constraints *string  `hcl:"constraints"`
+		Hashes      []string `hcl:"hashes,optional"`
+	} `hcl:"provider,block"`
+}
+
+// ParseLockFile parses .terraform.lock.hcl written by Terraform or hashi.
+func ParseLockFile(content string) (*LockFile, error) {
+	file, diags := hclparse.NewParser().ParseHCL([]byte(content), ".terraform.lock.hcl")
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	body := lockFileBody{}
+	if diags := gohcl.DecodeBody(file.Body, nil, &body); diags.HasErrors() {
+		return nil, diags
+	}
+
+	lockFile := &LockFile{}
+	for _, provider := range body.Providers {
+		entry := LockEntry{Provider: provider.Provider, Version: provider.Version, Hashes: provider.Hashes}
+		if provider.Constraints != nil {
+			entry.Constraints = *provider.Constraints
+		}
+		lockFile.Entries = append(lockFile.Entries, entry)
+	}
+
+	return lockFile, nil
+}
+
+func mergeHashes(hashes ...[]string) []string {
+	seen := map[string]bool{}
+	var merged []string
+	for _, list := range hashes {
+		for _, hash := range list {
+			if !seen[hash] {
+				seen[hash] = true
+				merged = append(merged, hash)
+			}
+		}
+	}
+
+	sort.Strings(merged)
+	return merged
+}
+
+// Merge adds entry to the lock file.
+// Hashes are merged if the provider is already locked at the same version, otherwise the entry is replaced.
+func (l *LockFile) Merge(entry LockEntry) {
+	for i, existing := range l.Entries {
+		if existing.Provider != entry.Provider {
+			continue
+		}
+
+		if len(entry.Constraints) == 0 {
+			entry.Constraints = existing.Constraints
+		}
+		if existing.Version == entry.Version {
+			entry.Hashes = mergeHashes(existing.Hashes, entry.Hashes)
+		} else {
+			entry.Hashes = mergeHashes(entry.Hashes)
+		}
+
+		l.Entries[i] = entry
+		return
+	}
+
+	entry.Hashes = mergeHashes(entry.Hashes)
+	l.Entries = append(l.Entries, entry)
+	sort.SliceStable(l.Entries, func(i, j int) bool {
+		return l.Entries[i].Provider < l.Entries[j].Provider
+	})
+}
+
+// String formats the lock file in the same way as Terraform.
+func (l *LockFile) String() string {
+	builder := strings.Builder{}
+	builder.WriteString(lockFileHeader)
+
+	for _, entry := range l.Entries {
+		builder.WriteString("\n")
+		builder.WriteString(fmt.Sprintf("provider %s {\n", strconv.Quote(entry.Provider)))
+		if len(entry.Constraints) > 0 {
+			builder.WriteString(fmt.Sprintf("  version     = %s\n", strconv.Quote(entry.Version)))
+			builder.WriteString(fmt.Sprintf("  constraints = %s\n", strconv.Quote(entry.Constraints)))
+		} else {
+			builder.WriteString(fmt.Sprintf("  version = %s\n", strconv.Quote(entry.Version)))
+		}
+
+		builder.WriteString("  hashes = [\n")
+		for _, hash := range entry.Hashes {
+			builder.WriteString(fmt.Sprintf("    %s,\n", strconv.Quote(hash)))
+		}
+		builder.WriteString("  ]\n")
+		builder.WriteString("}\n")
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,109 @@
+package providerutils
+
+import (
+	"reflect"
+	"testing"
+)
+
+const testLockFile = `# This file is maintained automatically by "terraform init".
+# Manual edits may be lost in future updates.
+
+provider "registry.terraform.io/hashicorp/aws" {
+  version     = "5.0.0"
+  constraints = "~> 5.0"
+  hashes = [
+    "h1:aaaa",
+    "zh:bbbb",
+  ]
+}
+
+provider "registry.terraform.io/hashicorp/null" {
+  version = "3.2.1"
+  hashes = [
+    "h1:cccc",
+  ]
+}
+`
+
+func TestParseLockFile(t *testing.T) {
+	lockFile, err := ParseLockFile(testLockFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []LockEntry{
+		{Provider: "registry.terraform.io/hashicorp/aws", Version: "5.0.0", Constraints: "~> 5.0", Hashes: []string{"h1:aaaa", "zh:bbbb"}},
+		{Provider: "registry.terraform.io/hashicorp/null", Version: "3.2.1", Hashes: []string{"h1:cccc"}},
+	}
+	if !reflect.DeepEqual(lockFile.Entries, expected) {
+		t.Errorf("%+v is not equal to %+v", lockFile.Entries, expected)
+	}
+
+	if lockFile.String() != testLockFile {
+		t.Errorf("lock file must be formatted in the same way:\n%s", lockFile.String())
+	}
+}
+
+func TestParseLockFileSyntax(t *testing.T) {
+	content := `# Written by hand
+provider "registry.terraform.io/hashicorp/aws" {
+  # pinned for the eu-west-1 stack
+  version="5.31.0" // latest tested
+  constraints = "~> 5.0"
+  hashes = ["h1:Y5EK6vYmJgkDdJfCH7VKeXnZFY0AUsC6lZ0KKY6lzJw=", /* darwin */ "zh:0cdb9c2083bf0902442384f7309367791e4640581652dda456f2d6d7abf0de8d"]
+}
+provider "registry.terraform.io/hashicorp/null" { version = "3.2.2" }
+`
+
+	lockFile, err := ParseLockFile(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []LockEntry{
+		{
+			Provider:    "registry.terraform.io/hashicorp/aws",
+			Version:     "5.31.0",
+			Constraints: "~> 5.0",
+			Hashes:      []string{"h1:Y5EK6vYmJgkDdJfCH7VKeXnZFY0AUsC6lZ0KKY6lzJw=", "zh:0cdb9c2083bf0902442384f7309367791e4640581652dda456f2d6d7abf0de8d"},
+		},
+		{Provider: "registry.terraform.io/hashicorp/null", Version: "3.2.2"},
+	}
+	if !reflect.DeepEqual(lockFile.Entries, expected) {
+		t.Errorf("%+v is not equal to %+v", lockFile.Entries, expected)
+	}
+}
+
+func TestParseLockFileInvalid(t *testing.T) {
+	for _, content := range []string{
+		"terraform {\n}\n",
+		"provider \"registry.terraform.io/hashicorp/aws\" {\n  version = \"5.0.0\"\n",
+		"provider \"registry.terraform.io/hashicorp/aws\" {\n  unknown = 1\n}\n",
+		"provider \"registry.terraform.io/hashicorp/aws\" {\n  hashes = [\"h1:aaaa\"]\n}\n",
+		"provider \"registry.terraform.io/hashicorp/aws\" {\n  version = \"5.0.0\"\n  hashes = \"h1:aaaa\"\n}\n",
+	} {
+		if _, err := ParseLockFile(content); err == nil {
+			t.Errorf("error must happen:\n%s", content)
+		}
+	}
+}
+
+func TestLockFile_Merge(t *testing.T) {
+	lockFile, err := ParseLockFile(testLockFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lockFile.Merge(LockEntry{Provider: "registry.terraform.io/hashicorp/aws", Version: "5.0.0", Hashes: []string{"zh:bbbb", "h1:dddd"}})
+	lockFile.Merge(LockEntry{Provider: "registry.terraform.io/hashicorp/null", Version: "3.2.2", Hashes: []string{"h1:eeee"}})
+	lockFile.Merge(LockEntry{Provider: "registry.terraform.io/hashicorp/local", Version: "2.4.0", Hashes: []string{"zh:ffff", "h1:ffff"}})
+
+	expected := []LockEntry{
+		{Provider: "registry.terraform.io/hashicorp/aws", Version: "5.0.0", Constraints: "~> 5.0", Hashes: []string{"h1:aaaa", "h1:dddd", "zh:bbbb"}},
+		{Provider: "registry.terraform.io/hashicorp/local", Version: "2.4.0", Hashes: []string{"h1:ffff", "zh:ffff"}},
+		{Provider: "registry.terraform.io/hashicorp/null", Version: "3.2.2", Hashes: []string{"h1:eeee"}},
+	}
+	if !reflect.DeepEqual(lockFile.Entries, expected) {
+		t.Errorf("%+v is not equal to %+v", lockFile.Entries, expected)
+	}
+}
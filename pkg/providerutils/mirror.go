@@ -0,0 +1,186 @@
+package providerutils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MirrorArchive represents an archive of a platform in the provider network mirror protocol.
+type MirrorArchive struct {
+	URL    string   `json:"url"`
+	Hashes []string `json:"hashes,omitempty"`
+}
+
+type mirrorVersions struct {
+	Versions map[string]struct{} `json:"versions"`
+}
+
+type mirrorArchives struct {
+	Archives map[string]MirrorArchive `json:"archives"`
+}
+
+type hashCacheEntry struct {
+	modTime time.Time
+	size    int64
+	hash    string
+}
+
+// MirrorHandler serves the provider network mirror protocol from a directory in the packed layout.
+//
+// See https://developer.hashicorp.com/terraform/internals/provider-network-mirror-protocol
+type MirrorHandler struct {
+	Dir string
+
+	mutex     sync.Mutex
+	hashCache map[string]hashCacheEntry
+}
+
+// NewMirrorHandler creates a MirrorHandler serving dir.
+func NewMirrorHandler(dir string) *MirrorHandler {
+	return &MirrorHandler{
+		Dir:       dir,
+		hashCache: map[string]hashCacheEntry{},
+	}
+}
+
+// ZipHash returns a "zh:" hash of a provider zip.
+func ZipHash(checksum []byte) string {
+	return "zh:" + hex.EncodeToString(checksum)
+}
+
+func (h *MirrorHandler) zipHash(filename string) (string, error) {
+	info, err := os.Stat(filename)
+	if err != nil {
+		return "", err
+	}
+
+	h.mutex.Lock()
+	entry, ok := h.hashCache[filename]
+	h.mutex.Unlock()
+	if ok && entry.modTime.Equal(info.ModTime()) && entry.size == info.Size() {
+		return entry.hash, nil
+	}
+
+	file, err := os.Open(filename)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = file.Close() }()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+
+	entry = hashCacheEntry{modTime: info.ModTime(), size: info.Size(), hash: ZipHash(hash.Sum(nil))}
+	h.mutex.Lock()
+	h.hashCache[filename] = entry
+	h.mutex.Unlock()
+
+	return entry.hash, nil
+}
+
+type packedFile struct {
+	Name     string
+	Version  string
+	Platform string
+}
+
+func (h *MirrorHandler) packedFiles(provider Provider) ([]packedFile, error) {
+	dir := filepath.Join(h.Dir, provider.Hostname, provider.Namespace, provider.Type)
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	pattern := regexp.MustCompile(`^` + regexp.QuoteMeta(provider.ProductName()) + `_([^_]+)_([^_]+_[^_]+)\.zip$`)
+	var files []packedFile
+	for _, info := range infos {
+		if matches := pattern.FindStringSubmatch(info.Name()); matches != nil && info.Mode().IsRegular() {
+			files = append(files, packedFile{Name: info.Name(), Version: matches[1], Platform: matches[2]})
+		}
+	}
+
+	return files, nil
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// ServeHTTP handles requests of index.json, <version>.json and provider zips.
+func (h *MirrorHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	parts := strings.Split(strings.TrimPrefix(path.Clean(r.URL.Path), "/"), "/")
+	if len(parts) != 4 {
+		http.NotFound(w, r)
+		return
+	}
+
+	provider, err := ParseProvider(strings.Join(parts[0:3], "/"))
+	if err != nil || provider.Hostname == "." || provider.Hostname == ".." {
+		http.NotFound(w, r)
+		return
+	}
+
+	files, err := h.packedFiles(provider)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	name := parts[3]
+	switch {
+	case name == "index.json":
+		versions := mirrorVersions{Versions: map[string]struct{}{}}
+		for _, file := range files {
+			versions.Versions[file.Version] = struct{}{}
+		}
+		writeJSON(w, versions)
+	case strings.HasSuffix(name, ".json"):
+		version := strings.TrimSuffix(name, ".json")
+		archives := mirrorArchives{Archives: map[string]MirrorArchive{}}
+		for _, file := range files {
+			if file.Version != version {
+				continue
+			}
+
+			hash, err := h.zipHash(filepath.Join(h.Dir, provider.Hostname, provider.Namespace, provider.Type, file.Name))
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			archives.Archives[file.Platform] = MirrorArchive{URL: file.Name, Hashes: []string{hash}}
+		}
+
+		if len(archives.Archives) == 0 {
+			http.NotFound(w, r)
+			return
+		}
+		writeJSON(w, archives)
+	default:
+		for _, file := range files {
+			if file.Name == name {
+				http.ServeFile(w, r, filepath.Join(h.Dir, provider.Hostname, provider.Namespace, provider.Type, file.Name))
+				return
+			}
+		}
+		http.NotFound(w, r)
+	}
+}
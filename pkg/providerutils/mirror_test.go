@@ -0,0 +1,113 @@
+package providerutils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func createPackedMirror(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "hashi-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	provider := Provider{Hostname: DefaultHostname, Namespace: DefaultNamespace, Type: "null"}
+	for _, platform := range [][2]string{{"linux", "amd64"}, {"darwin", "arm64"}} {
+		filename := provider.PackedPath(dir, "3.2.1", platform[0], platform[1])
+		if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filename, []byte(platform[0]), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+func getJSON(t *testing.T, url string, v interface{}) int {
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return resp.StatusCode
+}
+
+func TestMirrorHandler(t *testing.T) {
+	dir := createPackedMirror(t)
+	defer os.RemoveAll(dir)
+
+	server := httptest.NewServer(NewMirrorHandler(dir))
+	defer server.Close()
+
+	versions := mirrorVersions{}
+	if getJSON(t, server.URL+"/registry.terraform.io/hashicorp/null/index.json", &versions) != http.StatusOK {
+		t.Fatalf("index.json must be found")
+	}
+	if _, ok := versions.Versions["3.2.1"]; !ok || len(versions.Versions) != 1 {
+		t.Errorf("unexpected versions %v", versions.Versions)
+	}
+
+	archives := mirrorArchives{}
+	if getJSON(t, server.URL+"/registry.terraform.io/hashicorp/null/3.2.1.json", &archives) != http.StatusOK {
+		t.Fatalf("3.2.1.json must be found")
+	}
+
+	checksum := sha256.Sum256([]byte("linux"))
+	expected := MirrorArchive{
+		URL:    "terraform-provider-null_3.2.1_linux_amd64.zip",
+		Hashes: []string{"zh:" + hex.EncodeToString(checksum[:])},
+	}
+	actual := archives.Archives["linux_amd64"]
+	if actual.URL != expected.URL || len(actual.Hashes) != 1 || actual.Hashes[0] != expected.Hashes[0] {
+		t.Errorf("%+v is not equal to %+v", actual, expected)
+	}
+	if len(archives.Archives) != 2 {
+		t.Errorf("unexpected archives %v", archives.Archives)
+	}
+
+	resp, err := http.Get(server.URL + "/registry.terraform.io/hashicorp/null/" + expected.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(content) != "linux" {
+		t.Errorf("unexpected content %s", content)
+	}
+}
+
+func TestMirrorHandlerNotFound(t *testing.T) {
+	dir := createPackedMirror(t)
+	defer os.RemoveAll(dir)
+
+	server := httptest.NewServer(NewMirrorHandler(dir))
+	defer server.Close()
+
+	for _, p := range []string{
+		"/registry.terraform.io/hashicorp/aws/index.json",
+		"/registry.terraform.io/hashicorp/null/1.0.0.json",
+		"/registry.terraform.io/hashicorp/null/unknown.zip",
+		"/registry.terraform.io/hashicorp/null",
+		"/../hashicorp/null/index.json",
+	} {
+		if status := getJSON(t, server.URL+p, &mirrorVersions{}); status != http.StatusNotFound {
+			t.Errorf("%s: expected 404 but got %d", p, status)
+		}
+	}
+}
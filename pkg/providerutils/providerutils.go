@@ -0,0 +1,112 @@
+package providerutils
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	// DefaultHostname is the hostname of the public Terraform registry.
+	DefaultHostname = "registry.terraform.io"
+	// DefaultNamespace is the namespace of providers released by HashiCorp.
+	DefaultNamespace = "hashicorp"
+	// ProductPrefix is a prefix of provider products hosted on releases.hashicorp.com.
+	ProductPrefix = "terraform-provider-"
+)
+
+var namePattern = regexp.MustCompile(`^[0-9a-z]+(-[0-9a-z]+)*$`)
+
+// Provider represents a Terraform provider address.
+type Provider struct {
+	Hostname  string
+	Namespace string
+	Type      string
+}
+
+/*
+ParseProvider parses a provider source address.
+Only providers of the hashicorp namespace on the public registry are accepted,
+because the others are not released on releases.hashicorp.com.
+
+Examples
+
+  aws                                 -> registry.terraform.io/hashicorp/aws
+  hashicorp/aws                       -> registry.terraform.io/hashicorp/aws
+  registry.terraform.io/hashicorp/aws -> registry.terraform.io/hashicorp/aws
+*/
+func ParseProvider(source string) (Provider, error) {
+	provider := Provider{Hostname: DefaultHostname, Namespace: DefaultNamespace}
+
+	parts := strings.Split(strings.ToLower(source), "/")
+	switch len(parts) {
+	case 1:
+		provider.Type = parts[0]
+	case 2:
+		provider.Namespace, provider.Type = parts[0], parts[1]
+	case 3:
+		provider.Hostname, provider.Namespace, provider.Type = parts[0], parts[1], parts[2]
+	default:
+		return Provider{}, fmt.Errorf("invalid provider address %s", source)
+	}
+
+	provider.Type = strings.TrimPrefix(provider.Type, ProductPrefix)
+	for _, name := range []string{provider.Namespace, provider.Type} {
+		if !namePattern.MatchString(name) {
+			return Provider{}, fmt.Errorf("invalid provider address %s", source)
+		}
+	}
+	if len(provider.Hostname) == 0 || provider.Hostname == "." || provider.Hostname == ".." || strings.ContainsAny(provider.Hostname, `/\ `) {
+		return Provider{}, fmt.Errorf("invalid provider address %s", source)
+	}
+
+	// releases.hashicorp.com serves only providers released by HashiCorp on the public registry.
+	if provider.Hostname != DefaultHostname || provider.Namespace != DefaultNamespace {
+		return Provider{}, fmt.Errorf("provider %s is not released on releases.hashicorp.com, only %s/%s providers are supported", provider, DefaultHostname, DefaultNamespace)
+	}
+
+	return provider, nil
+}
+
+// String returns the fully qualified address of the provider.
+func (p Provider) String() string {
+	return fmt.Sprintf("%s/%s/%s", p.Hostname, p.Namespace, p.Type)
+}
+
+// ProductName returns the product name of the provider on releases.hashicorp.com.
+func (p Provider) ProductName() string {
+	return ProductPrefix + p.Type
+}
+
+// Platform returns a platform name used by Terraform, e.g. linux_amd64.
+func Platform(goos, goarch string) string {
+	return goos + "_" + goarch
+}
+
+/*
+UnpackedDir returns a directory of the provider in the unpacked layout of a filesystem mirror.
+
+Example
+
+  <pluginDir>/registry.terraform.io/hashicorp/aws/5.0.0/linux_amd64
+*/
+func (p Provider) UnpackedDir(pluginDir, version, goos, goarch string) string {
+	return filepath.Join(pluginDir, p.Hostname, p.Namespace, p.Type, version, Platform(goos, goarch))
+}
+
+// PackedFileName returns the file name of the provider zip, e.g. terraform-provider-aws_5.0.0_linux_amd64.zip.
+func (p Provider) PackedFileName(version, goos, goarch string) string {
+	return fmt.Sprintf("%s_%s_%s.zip", p.ProductName(), version, Platform(goos, goarch))
+}
+
+/*
+PackedPath returns a path of the provider zip in the packed layout of a filesystem mirror.
+
+Example
+
+  <pluginDir>/registry.terraform.io/hashicorp/aws/terraform-provider-aws_5.0.0_linux_amd64.zip
+*/
+func (p Provider) PackedPath(pluginDir, version, goos, goarch string) string {
+	return filepath.Join(pluginDir, p.Hostname, p.Namespace, p.Type, p.PackedFileName(version, goos, goarch))
+}
@@ -0,0 +1,65 @@
+package providerutils
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestParseProvider(t *testing.T) {
+	testCases := map[string]Provider{
+		"aws":                                 {Hostname: "registry.terraform.io", Namespace: "hashicorp", Type: "aws"},
+		"terraform-provider-aws":              {Hostname: "registry.terraform.io", Namespace: "hashicorp", Type: "aws"},
+		"hashicorp/aws":                       {Hostname: "registry.terraform.io", Namespace: "hashicorp", Type: "aws"},
+		"HashiCorp/AWS":                       {Hostname: "registry.terraform.io", Namespace: "hashicorp", Type: "aws"},
+		"registry.terraform.io/hashicorp/aws": {Hostname: "registry.terraform.io", Namespace: "hashicorp", Type: "aws"},
+	}
+
+	for source, expected := range testCases {
+		actual, err := ParseProvider(source)
+		if err != nil {
+			t.Errorf("%s: %s", source, err)
+		}
+		if actual != expected {
+			t.Errorf("%+v is not equal to %+v", actual, expected)
+		}
+	}
+}
+
+func TestParseProviderInvalid(t *testing.T) {
+	for _, source := range []string{
+		"", "a/b/c/d", "hashicorp/", "../aws", "hashicorp/a_b", "/hashicorp/aws",
+		"../hashicorp/aws", "./hashicorp/aws", `..\x/hashicorp/aws`,
+		"example.com/acme/cloud-stack", "acme/aws", "example.com/hashicorp/aws", "registry.terraform.io/acme/aws",
+	} {
+		if _, err := ParseProvider(source); err == nil {
+			t.Errorf("%s must be invalid", source)
+		}
+	}
+}
+
+func TestProvider_String(t *testing.T) {
+	provider := Provider{Hostname: "registry.terraform.io", Namespace: "hashicorp", Type: "aws"}
+	if provider.String() != "registry.terraform.io/hashicorp/aws" {
+		t.Errorf("unexpected address %s", provider.String())
+	}
+
+	if provider.ProductName() != "terraform-provider-aws" {
+		t.Errorf("unexpected product name %s", provider.ProductName())
+	}
+}
+
+func TestProvider_UnpackedDir(t *testing.T) {
+	provider := Provider{Hostname: "registry.terraform.io", Namespace: "hashicorp", Type: "aws"}
+	expected := filepath.Join("plugins", "registry.terraform.io", "hashicorp", "aws", "5.0.0", "linux_amd64")
+	if actual := provider.UnpackedDir("plugins", "5.0.0", "linux", "amd64"); actual != expected {
+		t.Errorf("expected %s but got %s", expected, actual)
+	}
+}
+
+func TestProvider_PackedPath(t *testing.T) {
+	provider := Provider{Hostname: "registry.terraform.io", Namespace: "hashicorp", Type: "aws"}
+	expected := filepath.Join("plugins", "registry.terraform.io", "hashicorp", "aws", "terraform-provider-aws_5.0.0_linux_amd64.zip")
+	if actual := provider.PackedPath("plugins", "5.0.0", "linux", "amd64"); actual != expected {
+		t.Errorf("expected %s but got %s", expected, actual)
+	}
+}
@@ -0,0 +1,40 @@
+package signatureutils
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/porkbeans/hashi/pkg/errorutils"
+	// TODO: golang.org/x/crypto/openpgp is deprecated and frozen, and it verifies every install now.
+	// Move to github.com/ProtonMail/go-crypto/openpgp, a maintained fork with the same API.
+	"golang.org/x/crypto/openpgp"
+)
+
+// LoadKeyRing reads OpenPGP public keys from filename, either ASCII armored or binary.
+func LoadKeyRing(filename string) (openpgp.EntityList, error) {
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	keyRing, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(content))
+	if err != nil {
+		if keyRing, err = openpgp.ReadKeyRing(bytes.NewReader(content)); err != nil {
+			return nil, fmt.Errorf("failed to read keys in %s: %s", filename, err)
+		}
+	}
+
+	return keyRing, nil
+}
+
+// Verify checks that signature is a detached signature of signed made by a key in keyRing.
+// The error matches errorutils.ErrSignatureInvalid if it is not.
+func Verify(keyRing openpgp.EntityList, signed, signature []byte) error {
+	_, err := openpgp.CheckDetachedSignature(keyRing, bytes.NewReader(signed), bytes.NewReader(signature))
+	if err != nil {
+		return fmt.Errorf("%w: %s", errorutils.ErrSignatureInvalid, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,115 @@
+package signatureutils
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/porkbeans/hashi/pkg/errorutils"
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/armor"
+)
+
+func newEntity(t *testing.T) *openpgp.Entity {
+	entity, err := openpgp.NewEntity("hashi", "test", "hashi@example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return entity
+}
+
+func sign(t *testing.T, entity *openpgp.Entity, content []byte) []byte {
+	signature := &bytes.Buffer{}
+	if err := openpgp.DetachSign(signature, entity, bytes.NewReader(content), nil); err != nil {
+		t.Fatal(err)
+	}
+	return signature.Bytes()
+}
+
+func writeKey(t *testing.T, filename string, entity *openpgp.Entity, armored bool) {
+	buf := &bytes.Buffer{}
+	if armored {
+		writer, err := armor.Encode(buf, openpgp.PublicKeyType, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := entity.Serialize(writer); err != nil {
+			t.Fatal(err)
+		}
+		_ = writer.Close()
+	} else if err := entity.Serialize(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(filename, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "hashi-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	trusted := newEntity(t)
+	untrusted := newEntity(t)
+	content := []byte("0123  terraform_0.11.14_linux_amd64.zip\n")
+
+	for _, armored := range []bool{true, false} {
+		filename := filepath.Join(tempDir, "key")
+		writeKey(t, filename, trusted, armored)
+
+		keyRing, err := LoadKeyRing(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := Verify(keyRing, content, sign(t, trusted, content)); err != nil {
+			t.Errorf("signature must be valid: %s", err)
+		}
+		if err := Verify(keyRing, append(content, '#'), sign(t, trusted, content)); !errors.Is(err, errorutils.ErrSignatureInvalid) {
+			t.Errorf("tampered content must be invalid")
+		}
+		if err := Verify(keyRing, content, sign(t, untrusted, content)); !errors.Is(err, errorutils.ErrSignatureInvalid) {
+			t.Errorf("signature of untrusted key must be invalid")
+		}
+	}
+}
+
+func TestLoadKeyRingInvalid(t *testing.T) {
+	file, err := ioutil.TempFile("", "hashi-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+
+	_, _ = file.WriteString("not a key")
+	_ = file.Close()
+
+	if _, err := LoadKeyRing(file.Name()); err == nil {
+		t.Errorf("error must happen")
+	}
+	if _, err := LoadKeyRing(file.Name() + ".missing"); err == nil {
+		t.Errorf("error must happen")
+	}
+}
+
+func TestHashiCorpKeyRing(t *testing.T) {
+	keyRing, err := HashiCorpKeyRing()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(keyRing) != 1 {
+		t.Fatalf("expected 1 key but got %d", len(keyRing))
+	}
+	if fingerprint := fmt.Sprintf("%X", keyRing[0].PrimaryKey.Fingerprint); fingerprint != "C874011F0AB405110D02105534365D9472D7468F" {
+		t.Errorf("unexpected fingerprint %s", fingerprint)
+	}
+}
@@ -0,0 +1,148 @@
+package urlutils
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// Default templates of URLs, following the layout of releases.hashicorp.com.
+const (
+	DefaultListingTemplate   = "{{.Base}}/{{.Product}}/"
+	DefaultZipTemplate       = "{{.Base}}/{{.Product}}/{{.Version}}/{{.Product}}_{{.Version}}_{{.OS}}_{{.Arch}}.zip"
+	DefaultSumsTemplate      = "{{.Base}}/{{.Product}}/{{.Version}}/{{.Product}}_{{.Version}}_SHA256SUMS"
+	DefaultSignatureTemplate = "{{.Base}}/{{.Product}}/{{.Version}}/{{.Product}}_{{.Version}}_SHA256SUMS.sig"
+)
+
+// DefaultMirror is releases.hashicorp.com.
+var DefaultMirror = Mirror{Name: "releases", Base: strings.TrimSuffix(HashicorpProductList, "/")}
+
+// MirrorParams represents values given to templates of Mirror.
+type MirrorParams struct {
+	Base    string
+	Product string
+	Version string
+	OS      string
+	Arch    string
+}
+
+/*
+Mirror represents a site serving HashiCorp releases.
+
+URLs are Go templates given MirrorParams. Empty templates fall back to the layout of releases.hashicorp.com.
+A flat repository can be described as follows.
+
+  Mirror{
+    Base: "https://artifactory.example.com/artifactory",
+    Listing: "{{.Base}}/generic-local/hashicorp/",
+    Zip: "{{.Base}}/generic-local/hashicorp/{{.Product}}-{{.Version}}-{{.OS}}-{{.Arch}}.zip",
+    Sums: "{{.Base}}/generic-local/hashicorp/{{.Product}}_{{.Version}}_SHA256SUMS",
+  }
+*/
+type Mirror struct {
+	Name      string
+	Base      string
+	Listing   string
+	Zip       string
+	Sums      string
+	Signature string
+}
+
+func orDefault(value, defaultValue string) string {
+	if len(value) == 0 {
+		return defaultValue
+	}
+	return value
+}
+
+func (m Mirror) render(kind, text string, params MirrorParams) (string, error) {
+	tmpl, err := template.New(kind).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s template of mirror %s: %s", kind, m.Name, err)
+	}
+
+	params.Base = strings.TrimSuffix(m.Base, "/")
+	buf := bytes.Buffer{}
+	if err := tmpl.Execute(&buf, params); err != nil {
+		return "", fmt.Errorf("invalid %s template of mirror %s: %s", kind, m.Name, err)
+	}
+
+	return buf.String(), nil
+}
+
+// ListingURL returns a URL of the page listing versions of product.
+func (m Mirror) ListingURL(product string) (string, error) {
+	return m.render("listing", orDefault(m.Listing, DefaultListingTemplate), MirrorParams{Product: product})
+}
+
+// ZipURL returns a URL of the zip of product's version for os and arch.
+func (m Mirror) ZipURL(product, version, os, arch string) (string, error) {
+	return m.render("zip", orDefault(m.Zip, DefaultZipTemplate), MirrorParams{Product: product, Version: version, OS: os, Arch: arch})
+}
+
+// SumsURL returns a URL of the SHA256SUMS file of product's version.
+func (m Mirror) SumsURL(product, version string) (string, error) {
+	return m.render("sums", orDefault(m.Sums, DefaultSumsTemplate), MirrorParams{Product: product, Version: version})
+}
+
+// SignatureURL returns a URL of the signature of the SHA256SUMS file of product's version.
+func (m Mirror) SignatureURL(product, version string) (string, error) {
+	return m.render("signature", orDefault(m.Signature, DefaultSignatureTemplate), MirrorParams{Product: product, Version: version})
+}
+
+// Validate checks that all templates are valid.
+func (m Mirror) Validate() error {
+	if _, err := m.ListingURL("product"); err != nil {
+		return err
+	}
+	if _, err := m.ZipURL("product", "1.0.0", "linux", "amd64"); err != nil {
+		return err
+	}
+	if _, err := m.SumsURL("product", "1.0.0"); err != nil {
+		return err
+	}
+	_, err := m.SignatureURL("product", "1.0.0")
+	return err
+}
+
+// Placeholders replaced by capturing groups in ZipPattern.
+const (
+	versionPlaceholder = "HASHIVERSIONPLACEHOLDER"
+	osPlaceholder      = "HASHIOSPLACEHOLDER"
+	archPlaceholder    = "HASHIARCHPLACEHOLDER"
+)
+
+/*
+ZipPattern returns a pattern matching the end of zip URLs of product.
+The version, the os and the arch are captured as "version", "os" and "arch".
+Base is not a part of the pattern, so that links on a listing served from another host match.
+The version must start with a digit, so that zips of products prefixed with product, like consul-template for consul, don't match.
+
+  {{.Base}}/{{.Product}}-{{.Version}}-{{.OS}}-{{.Arch}}.zip
+  -> /terraform-(?P<version>[0-9][^/]*?)-(?P<os>[^/_.-]+)-(?P<arch>[^/_.-]+)\.zip$
+*/
+func (m Mirror) ZipPattern(product string) (*regexp.Regexp, error) {
+	m.Base = ""
+	rendered, err := m.ZipURL(product, versionPlaceholder, osPlaceholder, archPlaceholder)
+	if err != nil {
+		return nil, err
+	}
+
+	pattern := regexp.QuoteMeta(rendered)
+	for _, group := range []struct{ placeholder, name, pattern string }{
+		{versionPlaceholder, "version", `[0-9][^/]*?`},
+		{osPlaceholder, "os", `[^/_.-]+`},
+		{archPlaceholder, "arch", `[^/_.-]+`},
+	} {
+		if !strings.Contains(pattern, group.placeholder) {
+			return nil, fmt.Errorf("zip template of mirror %s doesn't contain the %s", m.Name, group.name)
+		}
+
+		pattern = strings.Replace(pattern, group.placeholder, fmt.Sprintf("(?P<%s>%s)", group.name, group.pattern), 1)
+		pattern = strings.Replace(pattern, group.placeholder, "(?:"+group.pattern+")", -1)
+	}
+
+	return regexp.Compile(pattern + "$")
+}
@@ -0,0 +1,128 @@
+package urlutils
+
+import (
+	"testing"
+)
+
+func TestDefaultMirror(t *testing.T) {
+	zipURL, err := DefaultMirror.ZipURL("terraform", "0.11.11", "linux", "amd64")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := ProductZipURL("terraform", "0.11.11", "linux", "amd64"); zipURL != expected {
+		t.Errorf("expected %s but got %s", expected, zipURL)
+	}
+
+	sumsURL, err := DefaultMirror.SumsURL("terraform", "0.11.11")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := ProductZipChecksumURL("terraform", "0.11.11"); sumsURL != expected {
+		t.Errorf("expected %s but got %s", expected, sumsURL)
+	}
+
+	listingURL, err := DefaultMirror.ListingURL("terraform")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := ProductVersionListURL("terraform"); listingURL != expected {
+		t.Errorf("expected %s but got %s", expected, listingURL)
+	}
+
+	signatureURL, err := DefaultMirror.SignatureURL("terraform", "0.11.11")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := ProductZipChecksumURL("terraform", "0.11.11") + ".sig"; signatureURL != expected {
+		t.Errorf("expected %s but got %s", expected, signatureURL)
+	}
+}
+
+func TestMirrorTemplates(t *testing.T) {
+	mirror := Mirror{
+		Name:    "artifactory",
+		Base:    "https://artifactory.example.com/artifactory/",
+		Listing: "{{.Base}}/generic-local/hashicorp/",
+		Zip:     "{{.Base}}/generic-local/hashicorp/{{.Product}}-{{.Version}}-{{.OS}}-{{.Arch}}.zip",
+	}
+	if err := mirror.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	zipURL, _ := mirror.ZipURL("vault", "1.0.1", "darwin", "arm64")
+	if expected := "https://artifactory.example.com/artifactory/generic-local/hashicorp/vault-1.0.1-darwin-arm64.zip"; zipURL != expected {
+		t.Errorf("expected %s but got %s", expected, zipURL)
+	}
+
+	sumsURL, _ := mirror.SumsURL("vault", "1.0.1")
+	if expected := "https://artifactory.example.com/artifactory/vault/1.0.1/vault_1.0.1_SHA256SUMS"; sumsURL != expected {
+		t.Errorf("expected %s but got %s", expected, sumsURL)
+	}
+}
+
+func TestMirrorInvalidTemplates(t *testing.T) {
+	testCases := []Mirror{
+		{Name: "unclosed", Zip: "{{.Base}/{{.Product}}.zip"},
+		{Name: "unknown", Sums: "{{.Base}}/{{.Checksum}}"},
+	}
+
+	for _, mirror := range testCases {
+		if err := mirror.Validate(); err == nil {
+			t.Errorf("%s: error must happen", mirror.Name)
+		}
+	}
+}
+
+func TestMirrorZipPattern(t *testing.T) {
+	testCases := []struct {
+		zip     string
+		url     string
+		version string
+		os      string
+		arch    string
+	}{
+		{"", "https://releases.hashicorp.com/terraform/0.12.0-beta1/terraform_0.12.0-beta1_linux_amd64.zip", "0.12.0-beta1", "linux", "amd64"},
+		{"{{.Base}}/{{.Product}}-{{.Version}}-{{.OS}}-{{.Arch}}.zip", "https://mirror.example.com/terraform-1.0.0-rc1-darwin-arm64.zip", "1.0.0-rc1", "darwin", "arm64"},
+		{"{{.Base}}/{{.OS}}/{{.Product}}/{{.Version}}/{{.Product}}_{{.Version}}_{{.OS}}_{{.Arch}}.zip", "/windows/terraform/0.11.14/terraform_0.11.14_windows_386.zip", "0.11.14", "windows", "386"},
+	}
+
+	for _, testCase := range testCases {
+		pattern, err := Mirror{Zip: testCase.zip}.ZipPattern("terraform")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		matches := pattern.FindStringSubmatch(testCase.url)
+		if matches == nil {
+			t.Errorf("%s doesn't match %s", pattern, testCase.url)
+			continue
+		}
+		groups := map[string]string{}
+		for i, name := range pattern.SubexpNames() {
+			groups[name] = matches[i]
+		}
+		if groups["version"] != testCase.version || groups["os"] != testCase.os || groups["arch"] != testCase.arch {
+			t.Errorf("unexpected matches %v of %s", matches, testCase.url)
+		}
+	}
+
+	for _, testCase := range []struct{ zip, product, url string }{
+		{"{{.Base}}/{{.Product}}-{{.Version}}-{{.OS}}-{{.Arch}}.zip", "consul", "https://mirror.example.com/consul-template-0.20.0-linux-amd64.zip"},
+		{"{{.Base}}/{{.Product}}-{{.Version}}-{{.OS}}-{{.Arch}}.zip", "vault", "https://mirror.example.com/vault-ssh-helper-0.1.4-linux-amd64.zip"},
+		{"{{.Base}}/{{.Product}}_{{.Version}}_{{.OS}}_{{.Arch}}.zip", "vault", "https://mirror.example.com/vault_ssh-helper_0.1.4_linux_amd64.zip"},
+		{"", "consul", "https://releases.hashicorp.com/consul-template/0.20.0/consul-template_0.20.0_linux_amd64.zip"},
+	} {
+		pattern, err := Mirror{Zip: testCase.zip}.ZipPattern(testCase.product)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if pattern.MatchString(testCase.url) {
+			t.Errorf("%s must not match %s", pattern, testCase.url)
+		}
+	}
+
+	if _, err := (Mirror{Zip: "{{.Base}}/{{.Product}}-{{.Version}}.zip"}).ZipPattern("terraform"); err == nil {
+		t.Errorf("error must happen without os and arch")
+	}
+}
@@ -0,0 +1,48 @@
+package versionutils
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"strings"
+)
+
+// DotfileName returns the name of the version file of product, e.g. .terraform-version.
+func DotfileName(product string) string {
+	return "." + product + "-version"
+}
+
+// ReadDotfile reads a version spec from a version file.
+// The first line which is neither empty nor a comment is used.
+func ReadDotfile(filename string) (string, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = file.Close() }()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) > 0 && !strings.HasPrefix(line, "#") {
+			return line, nil
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", errors.New(filename + " is empty")
+}
+
+// FindDotfile looks for the version file of product in dir and its parent directories.
+// It returns the version spec and the path of the file.
+func FindDotfile(dir, product string) (string, string, bool, error) {
+	filename, found := FindUp(dir, DotfileName(product))
+	if !found {
+		return "", "", false, nil
+	}
+
+	spec, err := ReadDotfile(filename)
+	return spec, filename, true, err
+}
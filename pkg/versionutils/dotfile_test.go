@@ -0,0 +1,57 @@
+package versionutils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDotfileName(t *testing.T) {
+	if DotfileName("terraform") != ".terraform-version" {
+		t.Errorf("unexpected name %s", DotfileName("terraform"))
+	}
+}
+
+func TestFindDotfile(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "hashi-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	subDir := filepath.Join(tempDir, "modules", "vpc")
+	if err := os.MkdirAll(subDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	filename := filepath.Join(tempDir, ".terraform-version")
+	if err := ioutil.WriteFile(filename, []byte("# pinned by ops\n\n  latest:^0.11  \n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	spec, path, found, err := FindDotfile(subDir, "terraform")
+	if err != nil || !found {
+		t.Fatalf("dotfile must be found")
+	}
+	if spec != "latest:^0.11" || path != filename {
+		t.Errorf("unexpected spec %s in %s", spec, path)
+	}
+
+	if _, _, found, _ := FindDotfile(subDir, "vault"); found {
+		t.Errorf("dotfile of vault must not be found")
+	}
+}
+
+func TestReadDotfileEmpty(t *testing.T) {
+	file, err := ioutil.TempFile("", "hashi-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+	defer os.Remove(file.Name())
+
+	if _, err := ReadDotfile(file.Name()); err == nil {
+		t.Errorf("error must happen")
+	}
+}
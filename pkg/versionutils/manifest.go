@@ -0,0 +1,48 @@
+package versionutils
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// ManifestFileName is the name of the file listing versions of tools used in a directory.
+	ManifestFileName = ".hashi.yaml"
+	// Auto is a version resolved from the configuration of the module, e.g. required_version of Terraform.
+	Auto = "auto"
+)
+
+// Manifest maps products to their versions.
+//
+//	terraform: auto
+//	vault: 1.0.1
+type Manifest map[string]string
+
+// LoadManifest reads a manifest file.
+func LoadManifest(filename string) (Manifest, error) {
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := Manifest{}
+	if err := yaml.Unmarshal(content, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %s", filename, err)
+	}
+
+	return manifest, nil
+}
+
+// Products returns sorted product names in the manifest.
+func (m Manifest) Products() []string {
+	products := make([]string, 0, len(m))
+	for product := range m {
+		products = append(products, product)
+	}
+
+	sort.Strings(products)
+	return products
+}
@@ -0,0 +1,54 @@
+package versionutils
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/porkbeans/hashi/internal/ioutils"
+)
+
+func TestLoadManifest(t *testing.T) {
+	file, err := ioutil.TempFile("", "hashi-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ioutils.Remove(file.Name())
+
+	_, _ = file.WriteString("terraform: auto\nvault: 1.0.1\n")
+	ioutils.Close(file)
+
+	manifest, err := LoadManifest(file.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := Manifest{"terraform": "auto", "vault": "1.0.1"}
+	if !reflect.DeepEqual(manifest, expected) {
+		t.Errorf("%v is not equal to %v", manifest, expected)
+	}
+
+	if !reflect.DeepEqual(manifest.Products(), []string{"terraform", "vault"}) {
+		t.Errorf("unexpected products %v", manifest.Products())
+	}
+}
+
+func TestLoadManifestInvalid(t *testing.T) {
+	file, err := ioutil.TempFile("", "hashi-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ioutils.Remove(file.Name())
+
+	_, _ = file.WriteString("- terraform\n")
+	ioutils.Close(file)
+
+	if _, err := LoadManifest(file.Name()); err == nil {
+		t.Errorf("error must happen")
+	}
+
+	if _, err := LoadManifest(file.Name() + ".nonexistence"); !os.IsNotExist(err) {
+		t.Errorf("file must not exist")
+	}
+}
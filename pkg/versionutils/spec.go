@@ -0,0 +1,62 @@
+package versionutils
+
+import (
+	"errors"
+	"strings"
+)
+
+const (
+	// Latest is the newest stable version.
+	Latest = "latest"
+	// LatestPrefix is a prefix of the newest version matching a regular expression, e.g. latest:^0.11.
+	LatestPrefix = "latest:"
+	// LatestAllowed is the newest stable version satisfying required_version. It is the same as Auto.
+	LatestAllowed = "latest-allowed"
+	// MinRequired is the oldest stable version satisfying required_version.
+	MinRequired = "min-required"
+)
+
+// IsExact returns true if spec is a specific version, not a keyword needing a version list.
+func IsExact(spec string) bool {
+	switch spec {
+	case Auto, Latest, LatestAllowed, MinRequired:
+		return false
+	default:
+		return !strings.HasPrefix(spec, LatestPrefix)
+	}
+}
+
+// UsesConstraints returns true if spec is resolved with required_version constraints.
+func UsesConstraints(spec string) bool {
+	return spec == Auto || spec == LatestAllowed || spec == MinRequired
+}
+
+/*
+ResolveSpec resolves a version spec to a version in versions.
+
+Specs
+
+  1.5.7           the version itself
+  latest          the newest stable version
+  latest:<regex>  the newest version matching the regular expression
+  auto            the newest stable version satisfying constraints
+  latest-allowed  the same as auto
+  min-required    the oldest stable version satisfying constraints
+*/
+func ResolveSpec(spec string, versions []string, constraints string) (string, error) {
+	switch {
+	case IsExact(spec):
+		return strings.TrimPrefix(spec, "v"), nil
+	case spec == Latest:
+		return Newest(versions, "")
+	case strings.HasPrefix(spec, LatestPrefix):
+		return NewestMatching(versions, strings.TrimPrefix(spec, LatestPrefix))
+	case spec == MinRequired:
+		if len(constraints) == 0 {
+			return "", errors.New("min-required needs required_version")
+		}
+		return Oldest(versions, constraints)
+	default:
+		return Newest(versions, constraints)
+	}
+}
@@ -0,0 +1,52 @@
+package versionutils
+
+import "testing"
+
+func TestResolveSpec(t *testing.T) {
+	versions := []string{"1.6.0-alpha20230802", "1.5.7", "1.5.0", "1.4.6", "0.11.14"}
+
+	testCases := []struct {
+		spec        string
+		constraints string
+		expected    string
+	}{
+		{"1.4.0", "", "1.4.0"},
+		{"v1.4.0", "", "1.4.0"},
+		{"latest", "~> 1.4.0", "1.5.7"},
+		{`latest:^0\.11`, "", "0.11.14"},
+		{"auto", "~> 1.4.0", "1.4.6"},
+		{"latest-allowed", "~> 1.5", "1.5.7"},
+		{"min-required", ">= 1.5", "1.5.0"},
+	}
+
+	for _, testCase := range testCases {
+		actual, err := ResolveSpec(testCase.spec, versions, testCase.constraints)
+		if err != nil {
+			t.Errorf("%s: %s", testCase.spec, err)
+		}
+		if actual != testCase.expected {
+			t.Errorf("%s: expected %s but got %s", testCase.spec, testCase.expected, actual)
+		}
+	}
+}
+
+func TestResolveSpecMinRequiredWithoutConstraints(t *testing.T) {
+	if _, err := ResolveSpec(MinRequired, []string{"1.5.7"}, ""); err == nil {
+		t.Errorf("error must happen")
+	}
+}
+
+func TestIsExact(t *testing.T) {
+	for spec, expected := range map[string]bool{
+		"1.5.7":          true,
+		"latest":         false,
+		"latest:^1.5":    false,
+		"auto":           false,
+		"latest-allowed": false,
+		"min-required":   false,
+	} {
+		if IsExact(spec) != expected {
+			t.Errorf("IsExact(%s) must be %t", spec, expected)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package versionutils
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl2/gohcl"
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hclparse"
+)
+
+var (
+	terraformFileSchema = &hcl.BodySchema{
+		Blocks: []hcl.BlockHeaderSchema{{Type: "terraform"}},
+	}
+	terraformBlockSchema = &hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{{Name: "required_version"}},
+	}
+)
+
+// parseRequiredVersions returns values of required_version in top-level terraform blocks of a .tf or .tf.json file.
+func parseRequiredVersions(src []byte, filename string) ([]string, error) {
+	parser := hclparse.NewParser()
+
+	var file *hcl.File
+	var diags hcl.Diagnostics
+	if strings.HasSuffix(filename, ".json") {
+		file, diags = parser.ParseJSON(src, filename)
+	} else {
+		file, diags = parser.ParseHCL(src, filename)
+	}
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	content, _, diags := file.Body.PartialContent(terraformFileSchema)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	var versions []string
+	for _, block := range content.Blocks {
+		blockContent, _, diags := block.Body.PartialContent(terraformBlockSchema)
+		if diags.HasErrors() {
+			return nil, diags
+		}
+
+		attribute, ok := blockContent.Attributes["required_version"]
+		if !ok {
+			continue
+		}
+
+		var version string
+		if diags := gohcl.DecodeExpression(attribute.Expr, nil, &version); diags.HasErrors() {
+			return nil, diags
+		}
+		versions = append(versions, version)
+	}
+
+	return versions, nil
+}
+
+// TerraformRequiredVersions returns required_version constraints declared in *.tf and *.tf.json files in dir.
+func TerraformRequiredVersions(dir string) ([]string, error) {
+	var files []string
+	for _, pattern := range []string{"*.tf", "*.tf.json"} {
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, matches...)
+	}
+	sort.Strings(files)
+
+	var versions []string
+	for _, file := range files {
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		found, err := parseRequiredVersions(content, file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %s", file, err)
+		}
+
+		versions = append(versions, found...)
+	}
+
+	return versions, nil
+}
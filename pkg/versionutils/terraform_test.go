@@ -0,0 +1,124 @@
+package versionutils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const testTerraformConfig = `
+# terraform { required_version = "commented out" }
+/*
+terraform {
+  required_version = "commented out"
+}
+*/
+
+terraform {
+  required_version = "~> 1.5"
+
+  required_providers {
+    aws = {
+      source           = "hashicorp/aws"
+      version          = "~> 5.0"
+    }
+  }
+
+  backend "s3" {
+    key = "${var.prefix}/terraform.tfstate"
+  }
+}
+
+locals {
+  required_version = "not in terraform block"
+  policy           = <<-EOT
+    terraform {
+      required_version = "in heredoc"
+    }
+  EOT
+  names = [for name in var.names : "${name}-${lookup(var.suffixes, name, "{")}"]
+}
+
+module "vpc" {
+  source = "./vpc"
+  terraform = { required_version = "not a terraform block" }
+}
+
+terraform {
+  required_version = ">= 1.5.2"
+}
+`
+
+func TestParseRequiredVersionsHCL(t *testing.T) {
+	versions, err := parseRequiredVersions([]byte(testTerraformConfig), "main.tf")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"~> 1.5", ">= 1.5.2"}
+	if !reflect.DeepEqual(versions, expected) {
+		t.Errorf("%v is not equal to %v", versions, expected)
+	}
+}
+
+func TestParseRequiredVersionsHCLInvalid(t *testing.T) {
+	for _, src := range []string{
+		"terraform {\n",
+		"terraform {\n  required_version = \"~> 1.5\n}\n",
+		"/* terraform {}",
+		"locals {\n  a = <<EOT\n",
+	} {
+		if _, err := parseRequiredVersions([]byte(src), "main.tf"); err == nil {
+			t.Errorf("error must happen:\n%s", src)
+		}
+	}
+}
+
+func TestParseRequiredVersionsJSON(t *testing.T) {
+	testCases := map[string][]string{
+		`{"terraform": {"required_version": "~> 1.5"}}`:                    {"~> 1.5"},
+		`{"terraform": [{"required_version": "~> 1.5"}, {"backend": {}}]}`: {"~> 1.5"},
+		`{"resource": {"null_resource": {"a": {}}}}`:                       nil,
+	}
+
+	for src, expected := range testCases {
+		versions, err := parseRequiredVersions([]byte(src), "versions.tf.json")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(versions, expected) {
+			t.Errorf("%v is not equal to %v", versions, expected)
+		}
+	}
+}
+
+func TestTerraformRequiredVersions(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "hashi-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	files := map[string]string{
+		"main.tf":          testTerraformConfig,
+		"versions.tf.json": `{"terraform": {"required_version": "< 1.6"}}`,
+		"README.md":        `terraform { required_version = "ignored" }`,
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(tempDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	versions, err := TerraformRequiredVersions(tempDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"~> 1.5", ">= 1.5.2", "< 1.6"}
+	if !reflect.DeepEqual(versions, expected) {
+		t.Errorf("%v is not equal to %v", versions, expected)
+	}
+}
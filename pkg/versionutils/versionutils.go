@@ -0,0 +1,156 @@
+package versionutils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+
+	"github.com/hashicorp/go-version"
+)
+
+func pickVersion(versions []string, accept func(v *version.Version, raw string) bool, newest bool) (string, bool) {
+	var picked *version.Version
+	pickedRaw := ""
+	for _, raw := range versions {
+		v, err := version.NewVersion(raw)
+		if err != nil || !accept(v, raw) {
+			continue
+		}
+
+		if picked == nil || (newest && v.GreaterThan(picked)) || (!newest && v.LessThan(picked)) {
+			picked = v
+			pickedRaw = raw
+		}
+	}
+
+	return pickedRaw, picked != nil
+}
+
+func stableVersionsSatisfying(constraints string) (func(*version.Version, string) bool, error) {
+	var constraint version.Constraints
+	if len(constraints) > 0 {
+		var err error
+		if constraint, err = version.NewConstraint(constraints); err != nil {
+			return nil, err
+		}
+	}
+
+	return func(v *version.Version, raw string) bool {
+		if len(v.Prerelease()) > 0 || len(v.Metadata()) > 0 {
+			return false
+		}
+		return constraint == nil || constraint.Check(v)
+	}, nil
+}
+
+func notFound(constraints string) error {
+	if len(constraints) > 0 {
+		return errors.New("no version satisfies " + constraints)
+	}
+	return errors.New("no stable version found")
+}
+
+// Newest returns the newest stable version in versions satisfying constraints.
+// Any stable version is accepted if constraints is empty.
+func Newest(versions []string, constraints string) (string, error) {
+	accept, err := stableVersionsSatisfying(constraints)
+	if err != nil {
+		return "", err
+	}
+
+	if newest, found := pickVersion(versions, accept, true); found {
+		return newest, nil
+	}
+	return "", notFound(constraints)
+}
+
+// Oldest returns the oldest stable version in versions satisfying constraints.
+func Oldest(versions []string, constraints string) (string, error) {
+	accept, err := stableVersionsSatisfying(constraints)
+	if err != nil {
+		return "", err
+	}
+
+	if oldest, found := pickVersion(versions, accept, false); found {
+		return oldest, nil
+	}
+	return "", notFound(constraints)
+}
+
+// NewestMatching returns the newest version in versions matching a regular expression.
+// Pre-releases are also accepted if they match.
+func NewestMatching(versions []string, pattern string) (string, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return "", err
+	}
+
+	newest, found := pickVersion(versions, func(v *version.Version, raw string) bool {
+		return re.MatchString(raw)
+	}, true)
+	if !found {
+		return "", fmt.Errorf("no version matches %s", pattern)
+	}
+
+	return newest, nil
+}
+
+// Nearby returns at most n versions in versions around target in ascending order.
+// The newest ones are returned if target is not a valid version.
+func Nearby(versions []string, target string, n int) []string {
+	parsed := make(version.Collection, 0, len(versions))
+	raws := map[*version.Version]string{}
+	for _, raw := range versions {
+		v, err := version.NewVersion(raw)
+		if err != nil {
+			continue
+		}
+		parsed = append(parsed, v)
+		raws[v] = raw
+	}
+	sort.Sort(parsed)
+
+	index := len(parsed)
+	if t, err := version.NewVersion(target); err == nil {
+		index = sort.Search(len(parsed), func(i int) bool { return !parsed[i].LessThan(t) })
+	}
+
+	start := index - n/2
+	if start+n > len(parsed) {
+		start = len(parsed) - n
+	}
+	if start < 0 {
+		start = 0
+	}
+
+	var nearby []string
+	for i := start; i < len(parsed) && i < start+n; i++ {
+		nearby = append(nearby, raws[parsed[i]])
+	}
+
+	return nearby
+}
+
+// FindUp looks for name in dir and its parent directories, and returns the path of the first one found.
+func FindUp(dir string, name string) (string, bool) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", false
+	}
+
+	for {
+		candidate := filepath.Join(dir, name)
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return candidate, true
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
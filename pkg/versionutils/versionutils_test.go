@@ -0,0 +1,148 @@
+package versionutils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewest(t *testing.T) {
+	versions := []string{"1.6.0-alpha20230802", "1.5.7", "1.5.0", "1.4.6", "0.11.14", "invalid"}
+
+	testCases := map[string]string{
+		"":                 "1.5.7",
+		"~> 1.5":           "1.5.7",
+		"~> 1.4.0":         "1.4.6",
+		">= 0.11, < 0.12":  "0.11.14",
+		"~> 1.5, != 1.5.7": "1.5.0",
+	}
+
+	for constraints, expected := range testCases {
+		actual, err := Newest(versions, constraints)
+		if err != nil {
+			t.Errorf("%s: %s", constraints, err)
+		}
+		if actual != expected {
+			t.Errorf("%s: expected %s but got %s", constraints, expected, actual)
+		}
+	}
+}
+
+func TestNewestNotFound(t *testing.T) {
+	if _, err := Newest([]string{"1.5.7"}, "~> 2.0"); err == nil {
+		t.Errorf("error must happen")
+	}
+
+	if _, err := Newest([]string{"1.6.0-beta1"}, ""); err == nil {
+		t.Errorf("error must happen")
+	}
+
+	if _, err := Newest([]string{"1.5.7"}, "invalid"); err == nil {
+		t.Errorf("error must happen")
+	}
+}
+
+func TestFindUp(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "hashi-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	subDir := filepath.Join(tempDir, "a", "b")
+	if err := os.MkdirAll(subDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := filepath.Join(tempDir, "a", ".terraform-version")
+	if err := ioutil.WriteFile(expected, []byte("1.5.7\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	actual, found := FindUp(subDir, ".terraform-version")
+	if !found || actual != expected {
+		t.Errorf("expected %s but got %s", expected, actual)
+	}
+
+	if _, found := FindUp(subDir, ".hashi-nonexistence"); found {
+		t.Errorf("file must not be found")
+	}
+}
+
+func TestOldest(t *testing.T) {
+	versions := []string{"1.6.0-alpha20230802", "1.5.7", "1.5.0", "1.4.6", "0.11.14"}
+
+	testCases := map[string]string{
+		"":         "0.11.14",
+		"~> 1.5":   "1.5.0",
+		">= 1.4.1": "1.4.6",
+		">= 1.5.1": "1.5.7",
+	}
+
+	for constraints, expected := range testCases {
+		actual, err := Oldest(versions, constraints)
+		if err != nil {
+			t.Errorf("%s: %s", constraints, err)
+		}
+		if actual != expected {
+			t.Errorf("%s: expected %s but got %s", constraints, expected, actual)
+		}
+	}
+
+	if _, err := Oldest(versions, ">= 2.0"); err == nil {
+		t.Errorf("error must happen")
+	}
+}
+
+func TestNewestMatching(t *testing.T) {
+	versions := []string{"1.6.0-alpha20230802", "1.5.7", "1.5.0", "0.11.14", "0.11.9"}
+
+	testCases := map[string]string{
+		`^0\.11`: "0.11.14",
+		`^1\.`:   "1.6.0-alpha20230802",
+		`^1\.5`:  "1.5.7",
+	}
+
+	for pattern, expected := range testCases {
+		actual, err := NewestMatching(versions, pattern)
+		if err != nil {
+			t.Errorf("%s: %s", pattern, err)
+		}
+		if actual != expected {
+			t.Errorf("%s: expected %s but got %s", pattern, expected, actual)
+		}
+	}
+
+	if _, err := NewestMatching(versions, `^2\.`); err == nil {
+		t.Errorf("error must happen")
+	}
+
+	if _, err := NewestMatching(versions, `(`); err == nil {
+		t.Errorf("error must happen")
+	}
+}
+
+func TestNearby(t *testing.T) {
+	versions := []string{"0.11.14", "0.11.13", "0.12.0-beta1", "0.11.12", "0.12.0", "invalid", "0.11.11", "0.12.1"}
+
+	testCases := []struct {
+		target   string
+		n        int
+		expected []string
+	}{
+		{"0.11.15", 4, []string{"0.11.13", "0.11.14", "0.12.0-beta1", "0.12.0"}},
+		{"0.10.0", 3, []string{"0.11.11", "0.11.12", "0.11.13"}},
+		{"0.13.0", 2, []string{"0.12.0", "0.12.1"}},
+		{"invalid", 2, []string{"0.12.0", "0.12.1"}},
+		{"0.11.15", 10, []string{"0.11.11", "0.11.12", "0.11.13", "0.11.14", "0.12.0-beta1", "0.12.0", "0.12.1"}},
+	}
+
+	for _, testCase := range testCases {
+		actual := Nearby(versions, testCase.target, testCase.n)
+		if strings.Join(actual, ",") != strings.Join(testCase.expected, ",") {
+			t.Errorf("%s: expected %v but got %v", testCase.target, testCase.expected, actual)
+		}
+	}
+}